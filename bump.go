@@ -0,0 +1,102 @@
+// Package bump releases the next version of a git repository in-process, like the bump
+// command: it bumps the latest version tag, runs the hooks, commits their changes and
+// pushes the new tag. The config files of the repository apply as they do to the
+// command, and so do the BUMP_* environment variables of config fields, e.g.
+// BUMP_PREFIX. Variables of flags that are not config fields, like BUMP_DRY_RUN and
+// BUMP_REF, are not read; set the options instead.
+package bump
+
+import (
+	"context"
+	"io"
+	"log/slog"
+
+	"github.com/mrvinkel/bump/internal"
+)
+
+// levels to bump, see Options
+const (
+	LEVEL_MAJOR      = internal.LEVEL_MAJOR
+	LEVEL_MINOR      = internal.LEVEL_MINOR
+	LEVEL_PATCH      = internal.LEVEL_PATCH
+	LEVEL_PRERELEASE = internal.LEVEL_PRERELEASE
+	LEVEL_POST       = internal.LEVEL_POST
+	LEVEL_DEV        = internal.LEVEL_DEV
+	LEVEL_BUILD      = internal.LEVEL_BUILD
+	LEVEL_PROMOTE    = internal.LEVEL_PROMOTE
+	LEVEL_CALVER     = internal.LEVEL_CALVER
+)
+
+// Options of a release. The zero value bumps the patch version of the repository in
+// the working directory. Like flags, options that are set override the config.
+type Options struct {
+	// Dir is a directory in the repository, the working directory if empty
+	Dir string
+	// Level is what to bump, patch if empty. Numeric versions can bump a named
+	// segment of their format.
+	Level string
+	// Version is released instead of bumping a level, like bump set
+	Version string
+	// Channel bumps to a pre-release on the channel, e.g. beta
+	Channel string
+	// Prefix of the version tag, the prefix of the latest version if empty
+	Prefix string
+	// Build metadata of the new version
+	Build string
+	// Ref is the commit, branch or tag to release instead of HEAD
+	Ref string
+	// Remote is the git remote to fetch from and push to, origin if empty
+	Remote string
+	// DryRun computes the new version without changing the repository
+	DryRun bool
+	// NoVerify skips the checks that the worktree is clean and synced
+	NoVerify bool
+	// NoFetch skips fetching before verifying
+	NoFetch bool
+	// NoCommit leaves the changes of the pre-hook uncommitted
+	NoCommit bool
+	// SkipPreHook skips the pre-hook of the config
+	SkipPreHook bool
+	// SkipPostHook skips the post-hook of the config
+	SkipPostHook bool
+	// SignOff adds a Signed-off-by trailer to the release commit
+	SignOff bool
+	// ViaBranch pushes the changes to a release branch instead of tagging
+	ViaBranch bool
+	// Strict rejects tags and versions that do not follow the SemVer spec exactly
+	Strict bool
+	// AllowDowngrade allows releasing a Version lower than the latest version
+	AllowDowngrade bool
+	// Getenv looks up BUMP_* and git environment variables, os.Getenv if nil
+	Getenv func(string) string
+	// Logger receives the log of the release, nothing is logged if nil
+	Logger *slog.Logger
+	// Output receives the output of the hooks, it is discarded if nil
+	Output io.Writer
+}
+
+// Result of a release
+type Result struct {
+	// Previous is the latest version before the release
+	Previous string
+	// Version is the new version tag
+	Version string
+	// Commit is the hash of the tagged commit, empty with ViaBranch
+	Commit string
+	// ReleaseBranch is the branch pushed with ViaBranch, to be tagged by bump finalize
+	ReleaseBranch string
+	// Outputs of the pre-hook
+	Outputs map[string]string
+	// DryRun is set if nothing was changed
+	DryRun bool
+}
+
+// Release releases the next version. Releases of different repositories can run
+// concurrently.
+func Release(ctx context.Context, opts Options) (Result, error) {
+	result, err := internal.Release(ctx, internal.Options(opts))
+	if err != nil {
+		return Result{}, err
+	}
+	return Result(*result), nil
+}
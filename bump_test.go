@@ -0,0 +1,77 @@
+package bump_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/mrvinkel/bump"
+	"github.com/mrvinkel/bump/internal/gittest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReleaseDryRun(t *testing.T) {
+	dir, origin := gittest.NewRepo(t, map[string]string{})
+
+	result, err := bump.Release(t.Context(), bump.Options{Dir: dir, Level: bump.LEVEL_MINOR, Channel: "rc", DryRun: true, Getenv: gittest.Getenv(t)})
+	require.NoError(t, err)
+
+	assert.Equal(t, "v1.0.0", result.Previous)
+	assert.Equal(t, "v1.1.0-rc.1", result.Version)
+	assert.True(t, result.DryRun)
+	assert.Len(t, result.Commit, 40)
+
+	_, err = origin.Tag("v1.1.0-rc.1")
+	assert.ErrorIs(t, err, git.ErrTagNotFound)
+}
+
+func TestRelease(t *testing.T) {
+	dir, origin := gittest.NewRepo(t, map[string]string{
+		".bump.json": `{"preHook": ["echo \"$VERSION\" > VERSION", "echo NOTES=major >> \"$BUMP_OUTPUT\""]}`,
+	})
+
+	result, err := bump.Release(t.Context(), bump.Options{Dir: dir, Level: bump.LEVEL_MAJOR, Getenv: gittest.Getenv(t)})
+	require.NoError(t, err)
+
+	assert.Equal(t, "v2.0.0", result.Version)
+	assert.Equal(t, map[string]string{"NOTES": "major"}, result.Outputs)
+
+	// the tag is pushed and points at the release commit with the pre-hook changes
+	tag, err := origin.Tag("v2.0.0")
+	require.NoError(t, err)
+	assert.Equal(t, result.Commit, tag.Hash().String())
+	commit, err := origin.CommitObject(tag.Hash())
+	require.NoError(t, err)
+	assert.Equal(t, "Release Bot", commit.Author.Name)
+	assert.Equal(t, "v2.0.0\n", gittest.FileAt(t, origin, tag.Hash(), "VERSION"))
+
+	// the version exists now
+	_, err = bump.Release(t.Context(), bump.Options{Dir: dir, Version: "v2.0.0", Getenv: gittest.Getenv(t)})
+	assert.ErrorContains(t, err, "v2.0.0 is not greater than the latest version v2.0.0")
+
+	_, err = bump.Release(t.Context(), bump.Options{Dir: dir, Level: bump.LEVEL_MAJOR, Version: "v3.0.0"})
+	assert.EqualError(t, err, "only one of level and version can be set")
+}
+
+func TestReleaseOutput(t *testing.T) {
+	dir, _ := gittest.NewRepo(t, map[string]string{".bump.json": `{"preHook": ["echo \"pre $VERSION\"", "echo warning >&2"], "postHook": ["echo post"]}`})
+
+	out := &strings.Builder{}
+	_, err := bump.Release(t.Context(), bump.Options{Dir: dir, Getenv: gittest.Getenv(t), Output: out})
+	require.NoError(t, err)
+	assert.Equal(t, "pre v1.0.1\nwarning\npost\n", out.String())
+}
+
+func TestReleaseCanceled(t *testing.T) {
+	dir, origin := gittest.NewRepo(t, map[string]string{})
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	_, err := bump.Release(ctx, bump.Options{Dir: dir, Getenv: gittest.Getenv(t)})
+	assert.ErrorIs(t, err, context.Canceled)
+
+	_, err = origin.Tag("v1.0.1")
+	assert.ErrorIs(t, err, git.ErrTagNotFound)
+}
@@ -1,9 +1,12 @@
+//go:generate sh -c "go run . schema > ../../bump.schema.json"
+
 package main
 
 import (
+	"fmt"
 	"os"
 
-	"github.com/mrvinkel/bump/cmd/bump/internal"
+	"github.com/mrvinkel/bump/internal"
 	"github.com/spf13/cobra"
 )
 
@@ -12,31 +15,119 @@ var (
 		Use:     "patch",
 		Aliases: []string{"p", "pa"},
 		Short:   "Bump the patch version",
-		RunE:    internal.Bump(internal.BumpPatch),
+		RunE:    internal.BumpLevel(internal.LEVEL_PATCH),
 	}
 	minorCmd = &cobra.Command{
 		Use:     "minor",
 		Short:   "Bump the minor version",
 		Aliases: []string{"m", "mi"},
-		RunE:    internal.Bump(internal.BumpMinor),
+		RunE:    internal.BumpLevel(internal.LEVEL_MINOR),
 	}
 	majorCmd = &cobra.Command{
 		Use:     "major",
 		Short:   "Bump the major version",
 		Aliases: []string{"M", "ma"},
-		RunE:    internal.Bump(internal.BumpMajor),
+		RunE:    internal.BumpLevel(internal.LEVEL_MAJOR),
 	}
 	preReleaseCmd = &cobra.Command{
 		Use:     "prerelease",
 		Short:   "Bump the pre-release version",
 		Aliases: []string{"pre"},
-		RunE:    internal.BumpE(internal.BumpPreRelease),
+		RunE:    internal.BumpLevel(internal.LEVEL_PRERELEASE),
+	}
+	buildCmd = &cobra.Command{
+		Use:   "build",
+		Short: "Bump the build version (numeric)",
+		RunE:  internal.BumpLevel(internal.LEVEL_BUILD),
+	}
+	levelCmd = &cobra.Command{
+		Use:   "level <name>",
+		Short: "Bump a named segment of the version format (numeric)",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return internal.BumpLevel(args[0])(cmd, args)
+		},
+	}
+	postCmd = &cobra.Command{
+		Use:   "post",
+		Short: "Bump the post-release version (pep440)",
+		RunE:  internal.BumpLevel(internal.LEVEL_POST),
+	}
+	devCmd = &cobra.Command{
+		Use:   "dev",
+		Short: "Bump the dev release version (pep440)",
+		RunE:  internal.BumpLevel(internal.LEVEL_DEV),
+	}
+	setCmd = &cobra.Command{
+		Use:     "set <version>",
+		Aliases: []string{"to"},
+		Short:   "Release the given version",
+		Args:    cobra.ExactArgs(1),
+		RunE:    internal.BumpSet,
+	}
+	promoteCmd = &cobra.Command{
+		Use:   "promote",
+		Short: "Promote the pre-release to the next channel, or release it from the last channel",
+		RunE:  internal.BumpPromote,
+	}
+	calverCmd = &cobra.Command{
+		Use:   "calver",
+		Short: "Bump the calendar version to the current date",
+		RunE:  internal.BumpCalVer,
+	}
+	finalizeCmd = &cobra.Command{
+		Use:   "finalize [version]",
+		Short: "Tag a merged release branch created with --via-branch",
+		Args:  cobra.MaximumNArgs(1),
+		RunE:  internal.Finalize,
+	}
+	describeCmd = &cobra.Command{
+		Use:   "describe",
+		Short: "Print a build version for HEAD without tagging it, e.g. v1.4.3-dev.7+g1a2b3c4",
+		Args:  cobra.NoArgs,
+		RunE:  internal.Describe,
+	}
+	checkCmd = &cobra.Command{
+		Use:   "check [level]",
+		Short: "Check that a release would succeed, without releasing",
+		Args:  cobra.MaximumNArgs(1),
+		RunE:  internal.Check,
 	}
 	versionCmd = &cobra.Command{
 		Use:   "version",
 		Short: "Print the version of bump",
 		Run: func(cmd *cobra.Command, args []string) {
-			internal.Info("bump %s\n", internal.BumpVersion)
+			fmt.Println("bump " + internal.BumpVersion)
+		},
+	}
+	initCmd = &cobra.Command{
+		Use:   "init",
+		Short: "Write a .bump.yaml for the repository, proposing settings from the project files and tags",
+		Args:  cobra.NoArgs,
+		RunE:  internal.Init,
+	}
+	configCmd = &cobra.Command{
+		Use:   "config",
+		Short: "Inspect the config",
+	}
+	configShowCmd = &cobra.Command{
+		Use:   "show",
+		Short: "Print the effective config and where each value came from",
+		Args:  cobra.NoArgs,
+		RunE:  internal.ConfigShow,
+	}
+	schemaCmd = &cobra.Command{
+		Use:    "schema",
+		Short:  "Print the JSON schema of the config, see go generate",
+		Args:   cobra.NoArgs,
+		Hidden: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			schema, err := internal.GenerateSchema()
+			if err != nil {
+				return err
+			}
+			_, err = os.Stdout.Write(schema)
+			return err
 		},
 	}
 )
@@ -48,30 +139,74 @@ func main() {
 		Long:          `Bump those versions! Utility for bumping and pushing git tags`,
 		SilenceUsage:  true,
 		SilenceErrors: true,
-		RunE:          internal.Bump(internal.BumpPatch),
+		RunE:          internal.BumpLevel(internal.LEVEL_PATCH),
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if err := internal.FlagsFromEnv(cmd.Flags(), os.Getenv); err != nil {
+				return err
+			}
+			flags := cmd.Flags()
+			debug, _ := flags.GetBool("debug")
+			quiet, _ := flags.GetBool("quiet")
+			format, _ := flags.GetString("log-format")
+			file, _ := flags.GetString("log-file")
+			internal.SetLogLevel(debug, quiet)
+			if err := internal.SetupLogging(os.Stderr, format, file); err != nil {
+				return err
+			}
+			internal.Debug("bump", "version", internal.BumpVersion, "args", os.Args[1:])
+			return nil
+		},
 	}
 
-	internal.DebugFlag = root.PersistentFlags().BoolP("debug", "d", false, "Debug mode")
-	internal.QuietFlag = root.PersistentFlags().BoolP("quiet", "q", false, "Quiet - only output errors")
-	internal.DryRun = root.PersistentFlags().BoolP("dry-run", "x", false, "Do not create tags, only print what would be done")
-	internal.NoVerify = root.PersistentFlags().BoolP("no-verify", "n", false, "Do not check repository status before creating tags")
-	internal.NoFetch = root.PersistentFlags().BoolP("no-fetch", "f", false, "Do not fetch before verifying repository status")
-	internal.NoCommit = root.PersistentFlags().BoolP("no-commit", "c", false, "Do not commit changes to the repository")
-	internal.SkipPreHook = root.PersistentFlags().BoolP("skip-pre-hook", "s", false, "Skip any configured pre-hook")
-	internal.Prefix = root.PersistentFlags().StringP("prefix", "p", "", "Prefix for the version tag")
-	internal.Build = root.PersistentFlags().String("build", "", "Build metadata to prepend to the version tag")
-	internal.Alpha = root.PersistentFlags().BoolP("alpha", "a", false, "Bump the pre-release version to alpha.1")
-	internal.Beta = root.PersistentFlags().BoolP("beta", "b", false, "Bump the pre-release version to beta.1")
-	internal.RC = root.PersistentFlags().BoolP("rc", "r", false, "Bump the pre-release version to rc.1")
+	root.PersistentFlags().BoolP("debug", "d", false, "Debug mode")
+	root.PersistentFlags().BoolP("quiet", "q", false, "Quiet - only output errors")
+	root.PersistentFlags().String("log-format", internal.LOG_FORMAT_PLAIN, "Log format, plain, text or json")
+	root.PersistentFlags().String("log-file", "", "Append the log, including debug records, to the file")
+	root.PersistentFlags().BoolP("dry-run", "x", false, "Do not create tags, only print what would be done")
+	root.PersistentFlags().BoolP("no-verify", "n", false, "Do not check repository status before creating tags")
+	root.PersistentFlags().BoolP("no-fetch", "f", false, "Do not fetch before verifying repository status")
+	root.PersistentFlags().BoolP("no-commit", "c", false, "Do not commit changes to the repository")
+	root.PersistentFlags().BoolP("skip-pre-hook", "s", false, "Skip any configured pre-hook")
+	root.PersistentFlags().Bool("skip-post-hook", false, "Skip any configured post-hook")
+	root.PersistentFlags().Bool("sign-off", false, "Add a Signed-off-by trailer to the release commit")
+	root.PersistentFlags().Bool("via-branch", false, "Push changes to a release branch instead of tagging, see finalize")
+	root.PersistentFlags().Bool("strict", false, "Reject tags and versions that do not follow the SemVer spec exactly")
+	root.PersistentFlags().String("ref", "", "Commit, branch or tag to release instead of HEAD")
+	root.PersistentFlags().String("remote", "", "Git remote to fetch from and push to, origin by default")
+	root.PersistentFlags().StringP("prefix", "p", "", "Prefix for the version tag")
+	root.PersistentFlags().String("build", "", "Build metadata to prepend to the version tag")
+	root.PersistentFlags().String("channel", "", "Bump to a pre-release on the channel, see channels in the config")
+	root.PersistentFlags().BoolP("alpha", "a", false, "Bump to an alpha pre-release")
+	root.PersistentFlags().BoolP("beta", "b", false, "Bump to a beta pre-release")
+	root.PersistentFlags().BoolP("rc", "r", false, "Bump to an rc pre-release")
+
+	setCmd.Flags().Bool("allow-downgrade", false, "Allow releasing a version lower than the latest version")
+	initCmd.Flags().BoolP("yes", "y", false, "Accept the proposed settings without asking")
+	initCmd.Flags().Bool("json", false, "Write .bump.json instead of .bump.yaml")
+	describeCmd.Flags().String("style", internal.DESCRIBE_DEV, "Build version style, dev or snapshot")
 
 	root.AddCommand(versionCmd)
 	root.AddCommand(patchCmd)
 	root.AddCommand(minorCmd)
 	root.AddCommand(majorCmd)
 	root.AddCommand(preReleaseCmd)
+	root.AddCommand(promoteCmd)
+	root.AddCommand(setCmd)
+	root.AddCommand(buildCmd)
+	root.AddCommand(levelCmd)
+	root.AddCommand(postCmd)
+	root.AddCommand(devCmd)
+	root.AddCommand(calverCmd)
+	root.AddCommand(finalizeCmd)
+	root.AddCommand(describeCmd)
+	root.AddCommand(checkCmd)
+	root.AddCommand(initCmd)
+	root.AddCommand(configCmd)
+	configCmd.AddCommand(configShowCmd)
+	root.AddCommand(schemaCmd)
 
 	if err := root.Execute(); err != nil {
-		internal.Error("%v\n", err)
+		internal.Error(err.Error())
 		os.Exit(1)
 	}
 }
@@ -0,0 +1,721 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/mrvinkel/bump/semver"
+	"github.com/spf13/cobra"
+)
+
+var BumpVersion = "dev"
+
+// bumpState is what a new version is computed from
+type bumpState struct {
+	rc       *releaseContext
+	scheme   Scheme
+	previous Versioned
+	// versions are all existing versions of the scheme, highest first
+	versions []Versioned
+}
+
+// bumpFunc computes the new version of a release
+type bumpFunc func(bumpState) (Versioned, error)
+
+// BumpLevel bumps the latest version by level using the bump operation of its scheme
+func BumpLevel(level string) func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		return bump(cmd, "", levelBump(level))
+	}
+}
+
+func levelBump(level string) bumpFunc {
+	return func(state bumpState) (Versioned, error) {
+		switch v := state.previous.(type) {
+		case *semver.Version:
+			fn, ok := semverLevels[level]
+			if !ok {
+				return nil, fmt.Errorf("bumping %s is not supported by the %s scheme", level, SCHEME_SEMVER)
+			}
+			return semverBump(state, v, state.rc.bumpChannel(), fn)
+		case *PEP440Version:
+			return pep440Bump(state, v, state.rc.bumpChannel(), func(v *PEP440Version) (*PEP440Version, error) {
+				return BumpPEP440(v, level)
+			})
+		case *NumericVersion:
+			return numericBump(state.rc, v, level)
+		}
+		return nil, fmt.Errorf("%s can not be bumped by %s, use the bump command for the configured scheme", state.previous, level)
+	}
+}
+
+var semverLevels = map[string]func(*semver.Version) (*semver.Version, error){
+	LEVEL_MAJOR:      func(v *semver.Version) (*semver.Version, error) { return semver.BumpMajor(v), nil },
+	LEVEL_MINOR:      func(v *semver.Version) (*semver.Version, error) { return semver.BumpMinor(v), nil },
+	LEVEL_PATCH:      func(v *semver.Version) (*semver.Version, error) { return semver.BumpPatch(v), nil },
+	LEVEL_PRERELEASE: semver.BumpPreRelease,
+}
+
+// BumpPromote promotes the latest pre-release to the next channel, or to the release
+// when it is on the last channel. A channel flag promotes to that channel instead.
+func BumpPromote(cmd *cobra.Command, args []string) error {
+	return bump(cmd, "", promoteBump)
+}
+
+// promoteBump promotes to the channel of the flags or options only. The channel of the
+// config is where new pre-releases of the branch go, not where to promote to.
+func promoteBump(state bumpState) (Versioned, error) {
+	channel := state.rc.channel
+	switch v := state.previous.(type) {
+	case *semver.Version:
+		return semverBump(state, v, "", func(v *semver.Version) (*semver.Version, error) {
+			return PromoteVersion(v, Channels(state.rc.config), channel, state.versions)
+		})
+	case *PEP440Version:
+		return pep440Bump(state, v, "", func(v *PEP440Version) (*PEP440Version, error) {
+			return PromotePEP440(v, channel, state.versions)
+		})
+	}
+	return nil, fmt.Errorf("%s has no pre-release channels to promote", state.previous)
+}
+
+// BumpSet releases the version given as argument. The prefix of the latest version is
+// used unless the version has one or --prefix is set.
+func BumpSet(cmd *cobra.Command, args []string) error {
+	return bump(cmd, "", setBump(args[0]))
+}
+
+func setBump(version string) bumpFunc {
+	return func(state bumpState) (Versioned, error) {
+		rc := state.rc
+		if rc.channel != "" {
+			return nil, errors.New("pre-release flags can not be used with set, include the pre-release in the version")
+		}
+
+		prefix := versionPrefix(state.previous)
+		if rc.prefix != "" {
+			prefix = new(rc.prefix)
+		}
+		newVersion, err := SetVersion(state.scheme, version, state.previous, prefix, rc.build, rc.allowDowngrade)
+		if err != nil {
+			return nil, err
+		}
+
+		// a prefix in the version must match the prefix of the flags or config
+		if p := versionPrefix(newVersion); rc.prefix != "" && p != nil && *p != rc.prefix {
+			return nil, fmt.Errorf("the prefix %q of %s does not match the prefix %q", *p, version, rc.prefix)
+		}
+		return newVersion, nil
+	}
+}
+
+// BumpCalVer bumps a calendar version to the current date
+func BumpCalVer(cmd *cobra.Command, args []string) error {
+	return bump(cmd, SCHEME_CALVER, calverBump)
+}
+
+func calverBump(state bumpState) (Versioned, error) {
+	v, ok := state.previous.(*CalVersion)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a calendar version", state.previous)
+	}
+	newVersion, err := v.Next(time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	if state.rc.prefix != "" {
+		newVersion.Prefix = new(state.rc.prefix)
+	}
+	return newVersion, nil
+}
+
+// semverBump runs a SemVer bump and applies the prefix, build and the channel unless it
+// is empty. A pre-release continues from the highest existing version on its channel.
+func semverBump(state bumpState, previousVersion *semver.Version, channel string, fn func(*semver.Version) (*semver.Version, error)) (Versioned, error) {
+	newVersion, err := fn(previousVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	rc := state.rc
+	// reuse the prefix unless set
+	if rc.prefix != "" {
+		newVersion.Prefix = new(rc.prefix)
+	}
+
+	// never reuse build metadata
+	if rc.build != "" {
+		newVersion.Build = new(rc.build)
+	} else {
+		newVersion.Build = nil
+	}
+
+	if channel != "" {
+		channels := Channels(rc.config)
+		if !slices.Contains(channels, channel) {
+			return nil, fmt.Errorf("unknown channel %s, channels are %s", channel, SliceString(channels))
+		}
+		newVersion.SetChannel(channel, NextChannelNumber(state.versions, newVersion, channel))
+	} else if channel, _, ok := newVersion.Channel(); ok && versionExists(state.versions, newVersion) {
+		// e.g. the latest reachable pre-release is not the latest on the channel
+		newVersion.SetChannel(channel, NextChannelNumber(state.versions, newVersion, channel))
+	}
+	return newVersion, nil
+}
+
+// pep440Bump runs a PEP 440 bump and applies the prefix, build and the channel unless
+// it is empty. Build metadata is used as the local version.
+func pep440Bump(state bumpState, previousVersion *PEP440Version, channel string, fn func(*PEP440Version) (*PEP440Version, error)) (Versioned, error) {
+	newVersion, err := fn(previousVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	rc := state.rc
+	if rc.prefix != "" {
+		newVersion.Prefix = new(rc.prefix)
+	}
+
+	if rc.build != "" {
+		if err = newVersion.SetLocal(rc.build); err != nil {
+			return nil, err
+		}
+	}
+
+	if channel != "" {
+		if err = newVersion.SetPreRelease(channel); err != nil {
+			return nil, err
+		}
+		newVersion.PreNum = NextPEP440PreNum(state.versions, newVersion, newVersion.PreLabel)
+	} else if newVersion.PreLabel != "" && newVersion.Post == nil && newVersion.Dev == nil && versionExists(state.versions, newVersion) {
+		newVersion.PreNum = NextPEP440PreNum(state.versions, newVersion, newVersion.PreLabel)
+	}
+	return newVersion, nil
+}
+
+// versionExists returns true if one of the versions has the same tag as v
+func versionExists(versions []Versioned, v Versioned) bool {
+	return slices.ContainsFunc(versions, func(e Versioned) bool {
+		return e.String() == v.String()
+	})
+}
+
+// numericBump bumps a segment of a numeric version. The format sets the prefix and
+// there is no pre-release or build metadata, so those flags are rejected.
+func numericBump(rc *releaseContext, previousVersion *NumericVersion, level string) (Versioned, error) {
+	if rc.bumpChannel() != "" || rc.build != "" {
+		return nil, fmt.Errorf("pre-releases and build metadata are not supported by the %s scheme", SCHEME_NUMERIC)
+	}
+	if rc.prefix != "" && rc.prefix != previousVersion.Prefix() {
+		return nil, fmt.Errorf("the prefix of the %s scheme is set by the format", SCHEME_NUMERIC)
+	}
+	return previousVersion.Bump(level)
+}
+
+// bump runs the release flow for the command in the working directory. The scheme is
+// taken from the config unless a scheme name is given.
+func bump(cmd *cobra.Command, schemeName string, fn bumpFunc) error {
+	rc, err := commandContext(cmd)
+	if err != nil {
+		return err
+	}
+	_, err = release(commandCtx(cmd), rc, schemeName, fn)
+	return err
+}
+
+// release runs the release flow and returns what was released
+func release(ctx context.Context, rc *releaseContext, schemeName string, fn bumpFunc) (result *Result, err error) {
+	repo := rc.repo
+	if err := checkPush(rc); err != nil {
+		return nil, err
+	}
+
+	if rc.verify {
+		err := checkRepositoryStatus(ctx, rc)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return nil, err
+	}
+	target, err := releaseTarget(rc, head)
+	if err != nil {
+		return nil, err
+	}
+
+	scheme, previousVersion, newVersion, err := nextVersion(rc, schemeName, fn, target, head)
+	if err != nil {
+		return nil, err
+	}
+	config := rc.config
+	result = &Result{Previous: previousVersion.String(), Version: newVersion.String(), DryRun: rc.dryRun}
+
+	restore, err := checkGoModule(rc, scheme, previousVersion, newVersion)
+	if err != nil {
+		return nil, err
+	}
+	// a rewrite of the module is undone if the release fails before it is committed
+	committed := false
+	if restore != nil {
+		defer func() {
+			if err == nil || committed {
+				return
+			}
+			if restoreErr := restore(); restoreErr != nil {
+				rc.log.Warn("unable to restore the module files", "error", restoreErr)
+			}
+		}()
+	}
+
+	if err = ctx.Err(); err != nil {
+		return nil, err
+	}
+	outputs, err := runPreHook(ctx, rc, newVersion, previousVersion)
+	if err != nil {
+		rc.log.Debug("pre-hook failed", "error", err)
+		return nil, errors.New("pre-hook failed")
+	}
+	vars := releaseVars(newVersion, previousVersion, outputs)
+	result.Outputs = outputs
+
+	gitIdentities, err := repo.Identities()
+	if err != nil {
+		return nil, err
+	}
+	author, committer := ResolveIdentities(config, gitIdentities, rc.getenv)
+
+	data, err := messageData(repo, target, newVersion, previousVersion, outputs)
+	if err != nil {
+		return nil, err
+	}
+	data.Author = author.Name
+
+	if err = ctx.Err(); err != nil {
+		return nil, err
+	}
+	if rc.viaBranch {
+		if !rc.commit {
+			return nil, errors.New("--via-branch can not be used with --no-commit")
+		}
+		if target != head {
+			return nil, errors.New("--via-branch can not be used with --ref")
+		}
+		result.ReleaseBranch, err = commitToReleaseBranch(ctx, rc, data, author, committer)
+		if err != nil {
+			return nil, err
+		}
+		committed = true
+		return result, nil
+	}
+
+	if target != head && rc.commit {
+		hasChanges, _, err := repo.HasChanges()
+		if err != nil {
+			return nil, err
+		}
+		if hasChanges {
+			return nil, errors.New("pre-hook changes can only be committed when releasing HEAD, use --no-commit to release --ref without them")
+		}
+	}
+
+	commit, err := commitChanges(ctx, rc, data, author, committer)
+	if err != nil {
+		return nil, err
+	}
+	if !commit.IsZero() {
+		// tag the release commit with the changes of the pre-hook
+		target = commit
+		committed = true
+	}
+	result.Commit = target.String()
+
+	tagMessage := ""
+	if config != nil && config.TagMessage != nil {
+		tagMessage, err = RenderMessage(*config.TagMessage, data)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	rc.log.Info("tag", "previous", previousVersion.String(), "version", newVersion.String())
+	if tagMessage != "" {
+		rc.log.Debug("tag message", "message", tagMessage)
+		if err = CheckIdentity("committer", committer); err != nil {
+			return nil, err
+		}
+	}
+
+	if rc.dryRun {
+		rc.log.Info("dry run, will not create tag")
+		return result, nil
+	}
+
+	err = repo.TagAndPush(ctx, newVersion.String(), target, tagMessage, committer)
+	if err != nil {
+		return nil, err
+	}
+
+	err = runPostHook(ctx, rc, vars)
+	if err != nil {
+		rc.log.Debug("post-hook failed", "error", err)
+		return nil, errors.New("post-hook failed")
+	}
+	return result, nil
+}
+
+// nextVersion computes the new version of a release of the target and checks that it
+// is not tagged yet. The scheme is taken from the config unless a scheme name is given.
+func nextVersion(rc *releaseContext, schemeName string, fn bumpFunc, target, head plumbing.Hash) (Scheme, Versioned, Versioned, error) {
+	if schemeName != "" {
+		if rc.config == nil {
+			rc.config = DefaultConfig()
+		}
+		rc.config.Scheme = &schemeName
+	}
+	scheme, err := SchemeFor(rc.config)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if rc.channel != "" {
+		rc.log.Debug("bumping to channel", "channel", rc.channel)
+	}
+
+	// all versions are needed to find a free pre-release number, but only the
+	// reachable ones when releasing --ref are candidates for the previous version
+	versions, err := getVersions(rc, scheme, plumbing.ZeroHash)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	previousVersion := latestVersion(scheme, versions)
+	if target != head {
+		previousVersion, err = getLatestVersion(rc, scheme, target)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	newVersion, err := fn(bumpState{rc: rc, scheme: scheme, previous: previousVersion, versions: versions})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if err = scheme.Validate(newVersion); err != nil {
+		return nil, nil, nil, fmt.Errorf("invalid version %s: %w", newVersion, err)
+	}
+	// a tag of the version may not be normalized, e.g. v1.4.0-rc.1 of v1.4.0rc1
+	exists, err := rc.repo.HasTag(newVersion.String())
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if exists || versionExists(versions, newVersion) {
+		return nil, nil, nil, fmt.Errorf("tag %s already exists", newVersion)
+	}
+	return scheme, previousVersion, newVersion, nil
+}
+
+// checkGoModule checks the go.mod module path on major bumps of semantic versions.
+// A missing /vN suffix is an error with the go scheme and a warning otherwise, unless
+// configured with goModule. A rewrite returns a function restoring the files, and is an
+// error if the release will not commit it.
+func checkGoModule(rc *releaseContext, scheme Scheme, previousVersion, newVersion Versioned) (func() error, error) {
+	previous, ok := previousVersion.(*semver.Version)
+	if !ok {
+		return nil, nil
+	}
+	next, ok := newVersion.(*semver.Version)
+	if !ok {
+		return nil, nil
+	}
+
+	mode := GO_MODULE_WARN
+	if scheme.Name() == SCHEME_GO {
+		mode = GO_MODULE_ERROR
+	}
+	if rc.config != nil && rc.config.GoModule != nil {
+		mode = *rc.config.GoModule
+	}
+
+	// the tag would be on a commit with the old module path
+	if mode == GO_MODULE_REWRITE && (!rc.commit || rc.ref != "") {
+		mod, want, err := GoModuleMismatch(rc.root, previous, next)
+		if err != nil {
+			return nil, err
+		}
+		if mod != nil {
+			return nil, fmt.Errorf("module path %s in %s must be %s for %s, it can only be rewritten when the release commits HEAD, not with --no-commit or --ref", mod.Path, mod.File, want, next)
+		}
+		return nil, nil
+	}
+	return CheckGoModule(rc.log, rc.root, mode, previous, next, rc.dryRun)
+}
+
+// releaseTarget resolves the commit to tag, which is HEAD unless --ref is given
+func releaseTarget(rc *releaseContext, head plumbing.Hash) (plumbing.Hash, error) {
+	if rc.ref == "" {
+		return head, nil
+	}
+
+	target, err := rc.repo.ResolveRevision(rc.ref)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	rc.log.Debug("releasing ref", "ref", rc.ref, "commit", target)
+
+	if rc.verify {
+		onBranch, err := rc.repo.IsOnRemoteBranch(target)
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+		if !onBranch {
+			return plumbing.ZeroHash, fmt.Errorf("%s is not on the remote branch", rc.ref)
+		}
+	}
+	return target, nil
+}
+
+// getLatestVersion returns the latest version tag of the scheme. Only tags reachable
+// from the given commit are considered unless it is the zero hash.
+func getLatestVersion(rc *releaseContext, scheme Scheme, reachableFrom plumbing.Hash) (Versioned, error) {
+	versions, err := getVersions(rc, scheme, reachableFrom)
+	if err != nil {
+		return nil, err
+	}
+	return latestVersion(scheme, versions), nil
+}
+
+// latestVersion returns the first of the sorted versions, or the initial version of
+// the scheme if there are none
+func latestVersion(scheme Scheme, versions []Versioned) Versioned {
+	if len(versions) > 0 {
+		return versions[0]
+	}
+	return scheme.Initial()
+}
+
+// getVersions returns the version tags of the scheme, highest first. Only tags reachable
+// from the given commit are considered unless it is the zero hash.
+func getVersions(rc *releaseContext, scheme Scheme, reachableFrom plumbing.Hash) ([]Versioned, error) {
+	var tags []string
+	var err error
+	if reachableFrom.IsZero() {
+		tags, err = rc.repo.GetTags()
+	} else {
+		tags, err = rc.repo.GetTagsReachableFrom(reachableFrom)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rc.log.Debug("tags", "tags", SliceString(tags))
+
+	versions := make([]Versioned, 0)
+	for _, t := range tags {
+		v, err := scheme.Parse(t)
+		if errors.Is(err, semver.ErrNoVersion) {
+			rc.log.Debug("ignoring tag", "tag", t)
+			continue
+		} else if err != nil && rc.strict {
+			return nil, fmt.Errorf("invalid tag %s: %w", t, err)
+		} else if err != nil {
+			rc.log.Warn("invalid tag", "tag", t, "error", err)
+			continue
+		}
+		versions = append(versions, v)
+	}
+
+	rc.log.Debug("parsed versions", "versions", VersionSliceString(versions))
+
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].Compare(versions[j]) > 0
+	})
+
+	rc.log.Debug("sorted versions", "versions", VersionSliceString(versions))
+	return versions, nil
+}
+
+func checkRepositoryStatus(ctx context.Context, rc *releaseContext) error {
+	hasChanages, changes, err := rc.repo.HasChanges()
+	if err != nil {
+		return err
+	}
+	if hasChanages {
+		return fmt.Errorf("uncommitted changes:\n%s", changes)
+	}
+
+	if rc.fetch {
+		rc.log.Debug("fetching repository")
+		if err = rc.repo.Fetch(ctx); err != nil {
+			return err
+		}
+	}
+
+	synced, err := rc.repo.IsSynced()
+	if err != nil {
+		return err
+	}
+	if !synced {
+		return errors.New("unpushed changes")
+	}
+	return nil
+}
+
+func runPreHook(ctx context.Context, rc *releaseContext, newVersion, previousVersion Versioned) (map[string]string, error) {
+	config := rc.config
+	if config == nil || len(config.PreHook) == 0 {
+		return nil, nil
+	}
+	if rc.skipPreHook {
+		rc.log.Info("skipping pre-hook")
+		return nil, nil
+	}
+
+	env := releaseVars(newVersion, previousVersion, nil)
+	rc.log.Info("running pre-hook")
+	rc.log.Debug("pre-hook commands", "commands", config.PreHook)
+	outputs, err := RunWithOutput(ctx, rc.dir, *config.Shell, config.PreHook, rc.out, env)
+	if err != nil {
+		return nil, err
+	}
+	rc.log.Debug("pre-hook outputs", "outputs", outputs)
+	return outputs, nil
+}
+
+func runPostHook(ctx context.Context, rc *releaseContext, vars map[string]string) error {
+	config := rc.config
+	if config == nil || len(config.PostHook) == 0 {
+		return nil
+	}
+	if rc.skipPostHook {
+		rc.log.Info("skipping post-hook")
+		return nil
+	}
+
+	rc.log.Info("running post-hook")
+	rc.log.Debug("post-hook commands", "commands", config.PostHook)
+	return Run(ctx, rc.dir, *config.Shell, config.PostHook, rc.out, vars)
+}
+
+// releaseVars returns the variables available to messages and hooks. Outputs
+// from the pre-hook can not override the version variables.
+func releaseVars(newVersion, previousVersion Versioned, outputs map[string]string) map[string]string {
+	vars := make(map[string]string, len(outputs)+2)
+	for key, value := range outputs {
+		vars[key] = value
+	}
+	vars["VERSION"] = newVersion.String()
+	vars["PREVIOUS_VERSION"] = previousVersion.String()
+	return vars
+}
+
+func messageData(repo *Repo, target plumbing.Hash, newVersion, previousVersion Versioned, outputs map[string]string) (*MessageData, error) {
+	data, err := NewMessageData(newVersion, previousVersion, outputs)
+	if err != nil {
+		return nil, err
+	}
+
+	data.Branch, err = repo.Branch()
+	if err != nil {
+		return nil, err
+	}
+	data.CommitCount, err = repo.CommitsSince(target, tagName(previousVersion))
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// commitChanges commits and pushes the changes of the pre-hook. The hash of the commit
+// is returned, or the zero hash if nothing was committed.
+func commitChanges(ctx context.Context, rc *releaseContext, data *MessageData, author, committer Identity) (plumbing.Hash, error) {
+	if rc.config == nil || !rc.commit {
+		return plumbing.ZeroHash, nil
+	}
+	err := checkRepositoryStatus(ctx, rc)
+	if err == nil {
+		// no changes, nothing to commit
+		return plumbing.ZeroHash, nil
+	}
+
+	message, err := releaseCommitMessage(rc, data, author, committer)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	rc.log.Info("commit", "message", message)
+	rc.log.Debug("commit identities", "author", author, "committer", committer)
+	if rc.dryRun {
+		rc.log.Info("dry run, will not commit and push changes")
+		return plumbing.ZeroHash, nil
+	}
+	return rc.repo.CommitAndPush(ctx, message, author, committer)
+}
+
+// commitToReleaseBranch commits any pre-hook changes to a release branch instead of
+// the current branch. The commit is marked with trailers so bump finalize can find
+// and tag it once it has been merged, with the same pre-hook outputs. The name of the
+// branch is returned.
+func commitToReleaseBranch(ctx context.Context, rc *releaseContext, data *MessageData, author, committer Identity) (string, error) {
+	config := rc.config
+	if config == nil {
+		config = DefaultConfig()
+	}
+
+	branch, err := RenderMessage(*config.ReleaseBranch, data)
+	if err != nil {
+		return "", err
+	}
+
+	message, err := releaseCommitMessage(rc, data, author, committer)
+	if err != nil {
+		return "", err
+	}
+	trailers := append([]string{RELEASE_TRAILER + ": " + data.Version}, OutputTrailers(data.Outputs)...)
+	message = AddTrailers(message, trailers...)
+
+	rc.log.Info("release branch", "branch", branch)
+	rc.log.Info("commit", "message", message)
+	rc.log.Debug("commit identities", "author", author, "committer", committer)
+	if rc.dryRun {
+		rc.log.Info("dry run, will not create and push release branch")
+		return branch, nil
+	}
+
+	err = rc.repo.CommitToBranchAndPush(ctx, branch, message, author, committer)
+	if err != nil {
+		return "", err
+	}
+	rc.log.Info("pushed release branch, run bump finalize to tag it once it has been merged", "branch", branch, "version", data.Version)
+	return branch, nil
+}
+
+func releaseCommitMessage(rc *releaseContext, data *MessageData, author, committer Identity) (string, error) {
+	config := rc.config
+	if config == nil {
+		config = DefaultConfig()
+	}
+
+	message, err := RenderMessage(*config.Message, data)
+	if err != nil {
+		return "", err
+	}
+
+	if err = CheckIdentity("author", author); err != nil {
+		return "", err
+	}
+	if err = CheckIdentity("committer", committer); err != nil {
+		return "", err
+	}
+
+	var signOff *Identity
+	if rc.signOff {
+		signOff = &author
+	}
+	return AddTrailers(message, CommitTrailers(signOff, config.CoAuthors)...), nil
+}
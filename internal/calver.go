@@ -0,0 +1,256 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DEFAULT_CALVER_FORMAT = "YYYY.0M.MICRO"
+	calverSeparators      = ".-_"
+	calverMicro           = "MICRO"
+)
+
+type calverToken struct {
+	regex  string
+	format func(int) string
+	// value of the token for a date, nil for MICRO
+	date func(t time.Time, week bool) int
+}
+
+// calverTokens are the supported format tokens, see https://calver.org
+var calverTokens = map[string]calverToken{
+	"YYYY":  {`\d{4}`, strconv.Itoa, calverYear},
+	"YY":    {`0|[1-9]\d{0,2}`, func(v int) string { return strconv.Itoa(max(v-2000, 0)) }, calverYear},
+	"0Y":    {`\d{2,3}`, func(v int) string { return fmt.Sprintf("%02d", max(v-2000, 0)) }, calverYear},
+	"MM":    {`[1-9]|1[0-2]`, strconv.Itoa, func(t time.Time, _ bool) int { return int(t.Month()) }},
+	"0M":    {`0[1-9]|1[0-2]`, func(v int) string { return fmt.Sprintf("%02d", v) }, func(t time.Time, _ bool) int { return int(t.Month()) }},
+	"WW":    {`[1-9]|[1-4]\d|5[0-3]`, strconv.Itoa, calverWeek},
+	"0W":    {`0[1-9]|[1-4]\d|5[0-3]`, func(v int) string { return fmt.Sprintf("%02d", v) }, calverWeek},
+	"DD":    {`[1-9]|[12]\d|3[01]`, strconv.Itoa, func(t time.Time, _ bool) int { return t.Day() }},
+	"0D":    {`0[1-9]|[12]\d|3[01]`, func(v int) string { return fmt.Sprintf("%02d", v) }, func(t time.Time, _ bool) int { return t.Day() }},
+	"MICRO": {`0|[1-9]\d*`, strconv.Itoa, nil},
+}
+
+// longest tokens first so YYYY is matched before YY
+var calverTokenOrder = []string{"MICRO", "YYYY", "YY", "0Y", "MM", "0M", "WW", "0W", "DD", "0D"}
+
+func calverYear(t time.Time, week bool) int {
+	if week {
+		year, _ := t.ISOWeek()
+		return year
+	}
+	return t.Year()
+}
+
+func calverWeek(t time.Time, _ bool) int {
+	_, week := t.ISOWeek()
+	return week
+}
+
+// CalVer is the calendar versioning scheme with a format like YYYY.0M.MICRO
+type CalVer struct {
+	format string
+	// tokens and literal separators in format order
+	parts []string
+	week  bool
+	re    *regexp.Regexp
+}
+
+func NewCalVer(format string) (*CalVer, error) {
+	c := &CalVer{format: format}
+	seen := map[string]bool{}
+	regex := strings.Builder{}
+	regex.WriteString(`^(?P<prefix>[^0-9]*)`)
+
+	for rest := format; rest != ""; {
+		token := ""
+		for _, t := range calverTokenOrder {
+			if strings.HasPrefix(rest, t) {
+				token = t
+				break
+			}
+		}
+
+		if token == "" {
+			if !strings.ContainsRune(calverSeparators, rune(rest[0])) {
+				return nil, fmt.Errorf("invalid calver format %q: unexpected %q", format, rest[:1])
+			}
+			c.parts = append(c.parts, rest[:1])
+			regex.WriteString(regexp.QuoteMeta(rest[:1]))
+			rest = rest[1:]
+			continue
+		}
+
+		kind := calverKind(token)
+		if seen[kind] {
+			return nil, fmt.Errorf("invalid calver format %q: more than one %s", format, kind)
+		}
+		seen[kind] = true
+		c.parts = append(c.parts, token)
+		regex.WriteString("(" + calverTokens[token].regex + ")")
+		rest = rest[len(token):]
+	}
+
+	if !seen["year"] {
+		return nil, fmt.Errorf("invalid calver format %q: a year is required", format)
+	}
+	if seen["week"] && (seen["month"] || seen["day"]) {
+		return nil, fmt.Errorf("invalid calver format %q: weeks can not be combined with months or days", format)
+	}
+	if seen["micro"] && c.parts[len(c.parts)-1] != calverMicro {
+		return nil, fmt.Errorf("invalid calver format %q: MICRO must be last", format)
+	}
+	c.week = seen["week"]
+
+	regex.WriteString(`$`)
+	c.re = regexp.MustCompile(regex.String())
+	return c, nil
+}
+
+func calverKind(token string) string {
+	switch token {
+	case "YYYY", "YY", "0Y":
+		return "year"
+	case "MM", "0M":
+		return "month"
+	case "WW", "0W":
+		return "week"
+	case "DD", "0D":
+		return "day"
+	}
+	return "micro"
+}
+
+func (c *CalVer) Name() string {
+	return SCHEME_CALVER
+}
+
+func (c *CalVer) Format() string {
+	return c.format
+}
+
+func (c *CalVer) Parse(tag string) (Versioned, error) {
+	matches := c.re.FindStringSubmatch(tag)
+	if matches == nil {
+		return nil, fmt.Errorf("invalid version format, expected %s", c.format)
+	}
+
+	v := &CalVersion{scheme: c}
+	if matches[1] != "" {
+		v.Prefix = new(matches[1])
+	}
+	group := 2
+	for _, part := range c.parts {
+		if _, ok := calverTokens[part]; !ok {
+			continue
+		}
+		value, err := strconv.Atoi(matches[group])
+		if err != nil {
+			return nil, err
+		}
+		if calverKind(part) == "year" && part != "YYYY" {
+			value += 2000
+		}
+		v.Values = append(v.Values, value)
+		group++
+	}
+	return v, nil
+}
+
+func (c *CalVer) Validate(v Versioned) error {
+	return nil
+}
+
+func (c *CalVer) Initial() Versioned {
+	v := &CalVersion{scheme: c}
+	for _, part := range c.parts {
+		if _, ok := calverTokens[part]; ok {
+			v.Values = append(v.Values, 0)
+		}
+	}
+	return v
+}
+
+// CalVersion is a calendar version. Values holds the value of each token in the
+// format, with years as full years.
+type CalVersion struct {
+	Prefix *string
+	Values []int
+	scheme *CalVer
+}
+
+// Next returns the version for the given date. The MICRO counter is incremented if
+// the date parts are unchanged and reset otherwise.
+func (v *CalVersion) Next(now time.Time) (*CalVersion, error) {
+	next := &CalVersion{Prefix: v.Prefix, scheme: v.scheme}
+	samePeriod := true
+	i := 0
+	for _, part := range v.scheme.parts {
+		token, ok := calverTokens[part]
+		if !ok {
+			continue
+		}
+		if token.date == nil {
+			if samePeriod {
+				next.Values = append(next.Values, v.Values[i]+1)
+			} else {
+				next.Values = append(next.Values, 0)
+			}
+			i++
+			continue
+		}
+
+		value := token.date(now, v.scheme.week)
+		if samePeriod && value < v.Values[i] {
+			return nil, fmt.Errorf("latest version %s is newer than %s", v, now.Format(time.DateOnly))
+		}
+		if value != v.Values[i] {
+			samePeriod = false
+		}
+		next.Values = append(next.Values, value)
+		i++
+	}
+
+	if samePeriod && !strings.Contains(v.scheme.format, calverMicro) {
+		return nil, errors.New("version for this period already exists, add MICRO to the calver format to release more than once per period")
+	}
+	return next, nil
+}
+
+func (v *CalVersion) String() string {
+	b := strings.Builder{}
+	if v.Prefix != nil {
+		b.WriteString(*v.Prefix)
+	}
+	i := 0
+	for _, part := range v.scheme.parts {
+		token, ok := calverTokens[part]
+		if !ok {
+			b.WriteString(part)
+			continue
+		}
+		b.WriteString(token.format(v.Values[i]))
+		i++
+	}
+	return b.String()
+}
+
+func (v *CalVersion) Compare(other fmt.Stringer) int {
+	o, ok := other.(*CalVersion)
+	if !ok || len(o.Values) != len(v.Values) {
+		return strings.Compare(v.String(), other.String())
+	}
+	for i := range v.Values {
+		if v.Values[i] > o.Values[i] {
+			return greator
+		} else if v.Values[i] < o.Values[i] {
+			return less
+		}
+	}
+	return equal
+}
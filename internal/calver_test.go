@@ -0,0 +1,149 @@
+package internal_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mrvinkel/bump/internal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewCalVerInvalidFormat(t *testing.T) {
+	formats := []string{
+		"",
+		"MM.MICRO",
+		"YYYY.YY",
+		"YYYY.WW.DD",
+		"YYYY.MICRO.MM",
+		"YYYY/MM",
+		"YYYY.MM.foo",
+	}
+
+	for _, format := range formats {
+		t.Run(format, func(t *testing.T) {
+			_, err := internal.NewCalVer(format)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestCalVerParse(t *testing.T) {
+	type test struct {
+		format  string
+		version string
+		values  []int
+		err     bool
+	}
+
+	tests := []test{
+		{format: "YYYY.MM.MICRO", version: "2024.5.0", values: []int{2024, 5, 0}},
+		{format: "YYYY.MM.MICRO", version: "v2024.12.13", values: []int{2024, 12, 13}},
+		{format: "YYYY.0M.MICRO", version: "2024.05.1", values: []int{2024, 5, 1}},
+		{format: "YY.0M.0D", version: "24.05.07", values: []int{2024, 5, 7}},
+		{format: "0Y.0M", version: "app-06.11", values: []int{2006, 11}},
+		{format: "YYYY.WW", version: "2024.52", values: []int{2024, 52}},
+		{format: "YYYY.MM.MICRO", version: "2024.05.0", err: true},
+		{format: "YYYY.MM.MICRO", version: "2024.13.0", err: true},
+		{format: "YYYY.0M.MICRO", version: "2024.5.0", err: true},
+		{format: "YYYY.MM.MICRO", version: "1.2.3-alpha", err: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.format+" "+tc.version, func(t *testing.T) {
+			scheme, err := internal.NewCalVer(tc.format)
+			require.NoError(t, err)
+
+			actual, err := scheme.Parse(tc.version)
+			if tc.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.values, actual.(*internal.CalVersion).Values)
+			assert.Equal(t, tc.version, actual.String())
+		})
+	}
+}
+
+func TestCalVerNext(t *testing.T) {
+	type test struct {
+		format   string
+		previous string
+		now      time.Time
+		expected string
+		err      bool
+	}
+
+	may7 := time.Date(2024, 5, 7, 12, 0, 0, 0, time.UTC)
+
+	tests := []test{
+		{format: "YYYY.0M.MICRO", previous: "", now: may7, expected: "2024.05.0"},
+		{format: "YYYY.0M.MICRO", previous: "v2024.04.3", now: may7, expected: "v2024.05.0"},
+		{format: "YYYY.0M.MICRO", previous: "2024.05.3", now: may7, expected: "2024.05.4"},
+		{format: "YYYY.0M.MICRO", previous: "2024.06.0", now: may7, err: true},
+		{format: "YY.0M.0D", previous: "24.05.06", now: may7, expected: "24.05.07"},
+		{format: "YY.0M.0D", previous: "24.05.07", now: may7, err: true},
+		{format: "YYYY.WW", previous: "2024.18", now: may7, expected: "2024.19"},
+		// ISO week 1 of 2025 starts on 2024-12-30
+		{format: "YYYY.WW.MICRO", previous: "2024.52.2", now: time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC), expected: "2025.1.0"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.format+" "+tc.previous, func(t *testing.T) {
+			scheme, err := internal.NewCalVer(tc.format)
+			require.NoError(t, err)
+
+			previous := scheme.Initial()
+			if tc.previous != "" {
+				previous, err = scheme.Parse(tc.previous)
+				require.NoError(t, err)
+			}
+
+			actual, err := previous.(*internal.CalVersion).Next(tc.now)
+			if tc.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual.String())
+			assert.Equal(t, 1, actual.Compare(previous))
+		})
+	}
+}
+
+func TestCalVerCompare(t *testing.T) {
+	scheme, err := internal.NewCalVer("YYYY.0M.MICRO")
+	require.NoError(t, err)
+
+	parse := func(s string) internal.Versioned {
+		v, err := scheme.Parse(s)
+		require.NoError(t, err)
+		return v
+	}
+
+	assert.Equal(t, 0, parse("2024.05.1").Compare(parse("v2024.05.1")))
+	assert.Equal(t, 1, parse("2024.10.0").Compare(parse("2024.09.12")))
+	assert.Equal(t, -1, parse("2023.12.9").Compare(parse("2024.01.0")))
+}
+
+func TestSchemeFor(t *testing.T) {
+	scheme, err := internal.SchemeFor(nil)
+	require.NoError(t, err)
+	assert.Equal(t, internal.SCHEME_SEMVER, scheme.Name())
+
+	scheme, err = internal.SchemeFor(&internal.Config{Scheme: new("calver")})
+	require.NoError(t, err)
+	assert.Equal(t, internal.SCHEME_CALVER, scheme.Name())
+	assert.Equal(t, internal.DEFAULT_CALVER_FORMAT, scheme.(*internal.CalVer).Format())
+
+	_, err = internal.SchemeFor(&internal.Config{Scheme: new("calver"), Format: new("MM")})
+	assert.Error(t, err)
+
+	scheme, err = internal.SchemeFor(&internal.Config{Scheme: new("numeric")})
+	require.NoError(t, err)
+	assert.Equal(t, internal.DEFAULT_NUMERIC_FORMAT, scheme.(*internal.Numeric).Format())
+
+	_, err = internal.SchemeFor(&internal.Config{Scheme: new("unknown")})
+	assert.Error(t, err)
+}
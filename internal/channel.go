@@ -0,0 +1,118 @@
+package internal
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/mrvinkel/bump/semver"
+)
+
+// DEFAULT_CHANNELS are the pre-release channels when none are configured, lowest first
+var DEFAULT_CHANNELS = []string{"alpha", "beta", "rc"}
+
+// Channels returns the configured pre-release channels, lowest first
+func Channels(config *Config) []string {
+	if config == nil || len(config.Channels) == 0 {
+		return DEFAULT_CHANNELS
+	}
+	return config.Channels
+}
+
+// NextChannelNumber returns the number of a new pre-release of v on a channel: one
+// more than the highest existing version of v on the channel, or 1 if there is none
+func NextChannelNumber(versions []Versioned, v *semver.Version, channel string) int {
+	next := 1
+	for _, existing := range versions {
+		e, ok := existing.(*semver.Version)
+		if !ok || e.Major != v.Major || e.Minor != v.Minor || e.Patch != v.Patch || !samePrefix(e.Prefix, v.Prefix) {
+			continue
+		}
+		if c, n, ok := e.Channel(); ok && c == channel && n >= next {
+			next = n + 1
+		}
+	}
+	return next
+}
+
+func samePrefix(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// PromoteVersion moves a pre-release to the next channel, e.g. 1.3.0-beta.4 to
+// 1.3.0-rc.1, or to the release 1.3.0 when promoted past the last channel. If to is
+// set the version is promoted to that channel instead, which must come after the
+// current one.
+func PromoteVersion(v *semver.Version, channels []string, to string, versions []Versioned) (*semver.Version, error) {
+	channel, _, ok := v.Channel()
+	current := slices.Index(channels, channel)
+	if !ok || current < 0 {
+		return nil, fmt.Errorf("%s is not a pre-release on one of the channels %s", v, SliceString(channels))
+	}
+
+	next := semver.New(v.Prefix, v.Major, v.Minor, v.Patch, nil, v.Build)
+	if to == "" {
+		if current == len(channels)-1 {
+			return next, nil
+		}
+		to = channels[current+1]
+	}
+
+	target := slices.Index(channels, to)
+	if target < 0 {
+		return nil, fmt.Errorf("unknown channel %s, channels are %s", to, SliceString(channels))
+	}
+	if target <= current {
+		return nil, fmt.Errorf("can not promote %s from %s to %s", v, channel, to)
+	}
+	next.SetChannel(to, NextChannelNumber(versions, next, to))
+	return next, nil
+}
+
+// pep440Channels are the PEP 440 pre-release labels, lowest first
+var pep440Channels = []string{"a", "b", "rc"}
+
+// NextPEP440PreNum returns the number of a new pre-release of v with a label: one
+// more than the highest existing pre-release of v with the label, or 1
+func NextPEP440PreNum(versions []Versioned, v *PEP440Version, label string) int {
+	next := 1
+	for _, existing := range versions {
+		e, ok := existing.(*PEP440Version)
+		if !ok || e.Epoch != v.Epoch || compareRelease(e.Release, v.Release) != equal || !samePrefix(e.Prefix, v.Prefix) {
+			continue
+		}
+		if e.PreLabel == label && e.PreNum >= next {
+			next = e.PreNum + 1
+		}
+	}
+	return next
+}
+
+// PromotePEP440 moves a PEP 440 pre-release to the next label, a to b to rc to the
+// final release, or to the given channel
+func PromotePEP440(v *PEP440Version, to string, versions []Versioned) (*PEP440Version, error) {
+	current := slices.Index(pep440Channels, v.PreLabel)
+	if current < 0 {
+		return nil, fmt.Errorf("%s is not a pre-release", v)
+	}
+
+	next := v.copy()
+	next.PreLabel, next.PreNum, next.Post, next.Dev, next.Local = "", 0, nil, nil, nil
+	if to == "" {
+		if current == len(pep440Channels)-1 {
+			return next, nil
+		}
+		to = pep440Channels[current+1]
+	}
+
+	if err := next.SetPreRelease(to); err != nil {
+		return nil, err
+	}
+	if slices.Index(pep440Channels, next.PreLabel) <= current {
+		return nil, fmt.Errorf("can not promote %s from %s to %s", v, v.PreLabel, to)
+	}
+	next.PreNum = NextPEP440PreNum(versions, next, next.PreLabel)
+	return next, nil
+}
@@ -0,0 +1,152 @@
+package internal_test
+
+import (
+	"testing"
+
+	"github.com/mrvinkel/bump/internal"
+	"github.com/mrvinkel/bump/semver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func parseVersions(t *testing.T, tags ...string) []internal.Versioned {
+	versions := []internal.Versioned{}
+	for _, tag := range tags {
+		v, err := semver.Parse(tag)
+		require.NoError(t, err)
+		versions = append(versions, v)
+	}
+	return versions
+}
+
+func TestVersionChannel(t *testing.T) {
+	type test struct {
+		version string
+		channel string
+		number  int
+		ok      bool
+	}
+
+	tests := []test{
+		{version: "1.3.0-beta.4", channel: "beta", number: 4, ok: true},
+		{version: "1.3.0-rc", channel: "rc", number: 0, ok: true},
+		{version: "1.3.0"},
+		{version: "1.3.0-1"},
+		{version: "1.3.0-beta.x"},
+		{version: "1.3.0-beta.1.2"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.version, func(t *testing.T) {
+			v, err := semver.Parse(tc.version)
+			require.NoError(t, err)
+
+			channel, number, ok := v.Channel()
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.channel, channel)
+			assert.Equal(t, tc.number, number)
+		})
+	}
+}
+
+func TestNextChannelNumber(t *testing.T) {
+	versions := parseVersions(t, "v1.3.0-rc.2", "v1.3.0-rc.1", "v1.3.0-beta.4", "api-v1.3.0-rc.7", "v1.2.0-rc.9")
+
+	v, err := semver.Parse("v1.3.0")
+	require.NoError(t, err)
+	assert.Equal(t, 3, internal.NextChannelNumber(versions, v, "rc"))
+	assert.Equal(t, 5, internal.NextChannelNumber(versions, v, "beta"))
+	assert.Equal(t, 1, internal.NextChannelNumber(versions, v, "alpha"))
+
+	v, err = semver.Parse("v1.4.0")
+	require.NoError(t, err)
+	assert.Equal(t, 1, internal.NextChannelNumber(versions, v, "rc"))
+}
+
+func TestPromoteVersion(t *testing.T) {
+	type test struct {
+		version  string
+		channels []string
+		to       string
+		expected string
+		err      string
+	}
+
+	tests := []test{
+		{version: "1.3.0-beta.4", expected: "1.3.0-rc.1"},
+		{version: "1.3.0-alpha.2", expected: "1.3.0-beta.1"},
+		{version: "v1.3.0-rc.3", expected: "v1.3.0"},
+		{version: "1.3.0-alpha.2", to: "rc", expected: "1.3.0-rc.1"},
+		{version: "1.3.0-dev.5", channels: []string{"dev", "alpha", "beta", "rc"}, expected: "1.3.0-alpha.1"},
+		{version: "1.2.0-beta.1", expected: "1.2.0-rc.3"},
+		{version: "1.3.0-rc.1", to: "beta", err: "can not promote 1.3.0-rc.1 from rc to beta"},
+		{version: "1.3.0-beta.1", to: "gamma", err: "unknown channel gamma"},
+		{version: "1.3.0-dev.1", err: "1.3.0-dev.1 is not a pre-release on one of the channels alpha, beta, rc"},
+		{version: "1.3.0", err: "is not a pre-release"},
+	}
+
+	versions := parseVersions(t, "1.2.0-rc.2", "1.2.0-rc.1")
+	for _, tc := range tests {
+		t.Run(tc.version, func(t *testing.T) {
+			v, err := semver.Parse(tc.version)
+			require.NoError(t, err)
+
+			channels := tc.channels
+			if channels == nil {
+				channels = internal.DEFAULT_CHANNELS
+			}
+			actual, err := internal.PromoteVersion(v, channels, tc.to, versions)
+			if tc.err != "" {
+				assert.ErrorContains(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual.String())
+		})
+	}
+}
+
+func TestPromotePEP440(t *testing.T) {
+	type test struct {
+		version  string
+		to       string
+		expected string
+		err      bool
+	}
+
+	tests := []test{
+		{version: "1.3.0a2", expected: "1.3.0b1"},
+		{version: "1.3.0b4", expected: "1.3.0rc3"},
+		{version: "1.3.0rc1.dev2", expected: "1.3.0"},
+		{version: "1.3.0a1", to: "rc", expected: "1.3.0rc3"},
+		{version: "1.3.0rc1", to: "beta", err: true},
+		{version: "1.3.0", err: true},
+	}
+
+	versions := []internal.Versioned{}
+	for _, tag := range []string{"1.3.0rc2", "1.3.0rc1", "1.2.0b5"} {
+		v, err := internal.ParsePEP440Version(tag)
+		require.NoError(t, err)
+		versions = append(versions, v)
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.version, func(t *testing.T) {
+			v, err := internal.ParsePEP440Version(tc.version)
+			require.NoError(t, err)
+
+			actual, err := internal.PromotePEP440(v, tc.to, versions)
+			if tc.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual.String())
+		})
+	}
+}
+
+func TestChannels(t *testing.T) {
+	assert.Equal(t, []string{"alpha", "beta", "rc"}, internal.Channels(nil))
+	assert.Equal(t, []string{"dev", "rc"}, internal.Channels(&internal.Config{Channels: []string{"dev", "rc"}}))
+}
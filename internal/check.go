@@ -0,0 +1,342 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/spf13/cobra"
+)
+
+// checks of bump check, in the order they run
+const (
+	CHECK_CONFIG   = "config"
+	CHECK_WORKTREE = "worktree"
+	CHECK_REMOTE   = "remote"
+	CHECK_UPSTREAM = "upstream"
+	CHECK_HOOKS    = "hooks"
+	CHECK_VERSION  = "version"
+	CHECK_BRANCH   = "branch"
+)
+
+// CheckResult is the outcome of a check of bump check. Err is nil if the check passed.
+type CheckResult struct {
+	Name   string
+	Detail string
+	Err    error
+}
+
+// Check verifies that a release of the level, patch unless given as the first
+// argument, would succeed without changing the repository or the remote. Every check
+// is reported, and an error is returned if one of them failed.
+func Check(cmd *cobra.Command, args []string) error {
+	opts, err := flagOptions(cmd.Flags())
+	if err != nil {
+		return err
+	}
+	if len(args) > 0 {
+		opts.Level = args[0]
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	ws, err := openWorkspace(cwd, logger)
+	if err != nil {
+		return err
+	}
+
+	config, _, configErr := ResolveConfig(cmd, ws.root, ws.branch, os.Getenv)
+	rc := newReleaseContext(ws, config, opts, commandLogger(config), os.Getenv)
+	results := runChecks(commandCtx(cmd), rc, opts, configErr)
+
+	if err = WriteCheckReport(cmd.OutOrStdout(), results); err != nil {
+		return err
+	}
+	return checkError(results)
+}
+
+// CheckRelease runs the checks of bump check for a release with the options, see
+// Release. The error is only set if the checks could not run.
+func CheckRelease(ctx context.Context, opts Options) ([]CheckResult, error) {
+	log := opts.Logger
+	if log == nil {
+		log = slog.New(slog.DiscardHandler)
+	}
+	getenv := opts.Getenv
+	if getenv == nil {
+		getenv = os.Getenv
+	}
+	dir := opts.Dir
+	if dir == "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+		dir = cwd
+	}
+
+	ws, err := openWorkspace(dir, log)
+	if err != nil {
+		return nil, err
+	}
+
+	config, _, configErr := resolveConfig(ws.root, ws.branch, getenv, opts.layers())
+	rc := newReleaseContext(ws, config, opts, log, getenv)
+	return runChecks(ctx, rc, opts, configErr), nil
+}
+
+// runChecks runs the checks in order. The checks that need the config are skipped if
+// it is invalid, and the upstream check if the remote can not be listed.
+func runChecks(ctx context.Context, rc *releaseContext, opts Options, configErr error) []CheckResult {
+	results := []CheckResult{}
+	add := func(name, detail string, err error) bool {
+		results = append(results, CheckResult{Name: name, Detail: detail, Err: err})
+		return err == nil
+	}
+
+	if opts.Version != "" && opts.Level != "" {
+		configErr = errors.Join(configErr, errors.New("only one of level and version can be set"))
+	}
+	configOK := add(CHECK_CONFIG, configDetail(rc), configErr)
+
+	if rc.verify {
+		add(CHECK_WORKTREE, "clean", checkWorktree(rc))
+	}
+
+	refs, err := rc.repo.ListRemote(ctx)
+	if err != nil {
+		err = fmt.Errorf("unable to list %s: %w", rc.remote, err)
+	}
+	remoteOK := add(CHECK_REMOTE, rc.remote+" is reachable", err)
+
+	if rc.verify && remoteOK {
+		add(CHECK_UPSTREAM, "synced with "+rc.remote+"/"+rc.branch, checkUpstream(rc, refs))
+	}
+
+	if !configOK {
+		return results
+	}
+
+	detail, err := checkHooks(ctx, rc)
+	add(CHECK_HOOKS, detail, err)
+
+	detail, err = checkVersion(rc, opts, refs)
+	add(CHECK_VERSION, detail, err)
+
+	add(CHECK_BRANCH, "releasing from "+rc.branch+" is allowed", checkBranch(rc))
+	return results
+}
+
+func configDetail(rc *releaseContext) string {
+	scheme := SCHEME_SEMVER
+	if rc.config != nil && rc.config.Scheme != nil && *rc.config.Scheme != "" {
+		scheme = *rc.config.Scheme
+	}
+	return "scheme " + scheme
+}
+
+func checkWorktree(rc *releaseContext) error {
+	hasChanges, changes, err := rc.repo.HasChanges()
+	if err != nil {
+		return err
+	}
+	if hasChanges {
+		return fmt.Errorf("uncommitted changes:\n%s", changes)
+	}
+	return nil
+}
+
+func checkUpstream(rc *releaseContext, refs []*plumbing.Reference) error {
+	if rc.branch == "" {
+		return errors.New("HEAD is not on a branch")
+	}
+	return rc.repo.CheckSynced(refs, rc.branch)
+}
+
+// checkHooks checks that the shell and the programs of the hook commands that will run
+// exist, and are executable
+func checkHooks(ctx context.Context, rc *releaseContext) (string, error) {
+	config := rc.config
+	if config == nil {
+		return "no hooks", nil
+	}
+
+	hooks := map[string][]string{}
+	if !rc.skipPreHook && len(config.PreHook) > 0 {
+		hooks["pre-hook"] = config.PreHook
+	}
+	if !rc.skipPostHook && len(config.PostHook) > 0 {
+		hooks["post-hook"] = config.PostHook
+	}
+	if len(hooks) == 0 {
+		return "no hooks", nil
+	}
+
+	shell := strings.Fields(*config.Shell)
+	if len(shell) == 0 {
+		return "", errors.New("shell is empty")
+	}
+	if _, err := exec.LookPath(shell[0]); err != nil {
+		return "", fmt.Errorf("shell %s: %w", shell[0], err)
+	}
+
+	errs := []error{}
+	count := 0
+	for _, name := range []string{"pre-hook", "post-hook"} {
+		for _, command := range hooks[name] {
+			count++
+			program := hookProgram(command)
+			if program == "" {
+				continue
+			}
+			if err := checkProgram(ctx, rc.dir, shell, program); err != nil {
+				errs = append(errs, fmt.Errorf("%s %q: %w", name, command, err))
+			}
+		}
+	}
+	return fmt.Sprintf("%d commands", count), errors.Join(errs...)
+}
+
+// hookProgram returns the program a hook command runs, the first word that is not a
+// variable assignment. An empty string is returned if it can not be known without
+// running the command, e.g. if it is a variable.
+func hookProgram(command string) string {
+	for _, word := range strings.Fields(command) {
+		if strings.Contains(word, "=") && !strings.HasPrefix(word, "=") {
+			continue
+		}
+		word = strings.Trim(word, `"'`)
+		if strings.ContainsAny(word, "$`") {
+			return ""
+		}
+		return word
+	}
+	return ""
+}
+
+// checkProgram checks that a program exists and is executable. A path is relative to
+// the directory the hooks run in, other programs are looked up by the shell, so
+// builtins and keywords are found too.
+func checkProgram(ctx context.Context, dir string, shell []string, program string) error {
+	if strings.Contains(program, "/") {
+		path := program
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return fmt.Errorf("%s does not exist", program)
+		}
+		if info.IsDir() || info.Mode()&0o111 == 0 {
+			return fmt.Errorf("%s is not executable", program)
+		}
+		return nil
+	}
+
+	cmdLine := append(append([]string{}, shell...), `command -v "$0" >/dev/null`, program)
+	cmd := exec.CommandContext(ctx, cmdLine[0], cmdLine[1:]...)
+	cmd.Dir = dir
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s not found", program)
+	}
+	return nil
+}
+
+// checkVersion computes the version the release would tag and checks that the tag does
+// not exist locally or on the remote. The go module is checked as for a dry run. The
+// release context is copied, as computing the version sets the scheme of the config.
+func checkVersion(rc *releaseContext, opts Options, refs []*plumbing.Reference) (string, error) {
+	dryRun := *rc
+	dryRun.dryRun = true
+	if rc.config != nil {
+		config := *rc.config
+		dryRun.config = &config
+	}
+	rc = &dryRun
+
+	head, err := rc.repo.Head()
+	if err != nil {
+		return "", err
+	}
+	target, err := releaseTarget(rc, head)
+	if err != nil {
+		return "", err
+	}
+
+	schemeName, fn := opts.bumpFunc()
+	scheme, previousVersion, newVersion, err := nextVersion(rc, schemeName, fn, target, head)
+	if err != nil {
+		return "", err
+	}
+	detail := fmt.Sprintf("%s -> %s", previousVersion, newVersion)
+
+	tag := plumbing.NewTagReferenceName(newVersion.String())
+	for _, ref := range refs {
+		if ref.Name() == tag {
+			return detail, fmt.Errorf("tag %s already exists on %s", newVersion, rc.remote)
+		}
+	}
+
+	_, err = checkGoModule(rc, scheme, previousVersion, newVersion)
+	return detail, err
+}
+
+// checkBranch checks that the config allows releasing from the branch the way it is set up
+func checkBranch(rc *releaseContext) error {
+	if err := pushAllowed(rc.config, rc.branch); err != nil {
+		return err
+	}
+	if rc.viaBranch && !rc.commit {
+		return errors.New("--via-branch can not be used with --no-commit")
+	}
+	if rc.viaBranch && rc.ref != "" {
+		return errors.New("--via-branch can not be used with --ref")
+	}
+	return nil
+}
+
+// WriteCheckReport writes a line for each check. The lines of multi-line errors are
+// indented below it.
+func WriteCheckReport(w io.Writer, results []CheckResult) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, result := range results {
+		status, message := "ok", result.Detail
+		if result.Err != nil {
+			status, message = "FAIL", result.Err.Error()
+		}
+		lines := strings.Split(message, "\n")
+		if _, err := fmt.Fprintf(tw, "%s\t%s\t%s\n", status, result.Name, lines[0]); err != nil {
+			return err
+		}
+		for _, line := range lines[1:] {
+			if _, err := fmt.Fprintf(tw, "\t\t%s\n", line); err != nil {
+				return err
+			}
+		}
+	}
+	return tw.Flush()
+}
+
+// checkError returns an error if one of the checks failed
+func checkError(results []CheckResult) error {
+	failed := 0
+	for _, result := range results {
+		if result.Err != nil {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d checks failed", failed, len(results))
+	}
+	return nil
+}
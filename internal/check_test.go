@@ -0,0 +1,191 @@
+package internal_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/mrvinkel/bump/internal"
+	"github.com/mrvinkel/bump/internal/gittest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckRelease(t *testing.T) {
+	type test struct {
+		files map[string]string
+		setup func(t *testing.T, dir string, origin *git.Repository)
+		opts  internal.Options
+		// failed maps the failing checks to a part of their error
+		failed  map[string]string
+		checks  []string
+		version string
+	}
+
+	all := []string{internal.CHECK_CONFIG, internal.CHECK_WORKTREE, internal.CHECK_REMOTE, internal.CHECK_UPSTREAM, internal.CHECK_HOOKS, internal.CHECK_VERSION, internal.CHECK_BRANCH}
+	tests := map[string]test{
+		"ok": {
+			files: map[string]string{
+				".bump.json": `{"preHook": ["echo \"$VERSION\" > VERSION", "FOO=1 ./release.sh"], "postHook": ["if true; then true; fi"]}`,
+				"release.sh": "#!/bin/sh\n",
+			},
+			setup: func(t *testing.T, dir string, origin *git.Repository) {
+				require.NoError(t, os.Chmod(filepath.Join(dir, "release.sh"), 0o755))
+				gittest.Commit(t, dir, map[string]string{}, "chmod")
+				gittest.Push(t, dir)
+			},
+			opts:    internal.Options{Level: internal.LEVEL_MINOR},
+			checks:  all,
+			version: "v1.0.0 -> v1.1.0",
+		},
+		"uncommitted and unpushed": {
+			setup: func(t *testing.T, dir string, origin *git.Repository) {
+				gittest.Commit(t, dir, map[string]string{"a.txt": "a"}, "a")
+				gittest.WriteFiles(t, dir, map[string]string{"readme.md": "changed"})
+			},
+			checks: all,
+			failed: map[string]string{
+				internal.CHECK_WORKTREE: "uncommitted changes:\n M readme.md",
+				internal.CHECK_UPSTREAM: "unpushed changes",
+			},
+		},
+		"behind": {
+			setup: func(t *testing.T, dir string, origin *git.Repository) {
+				// push a commit to origin from a clone
+				clone := filepath.Join(t.TempDir(), "clone")
+				_, err := git.PlainClone(clone, false, &git.CloneOptions{URL: filepath.Join(filepath.Dir(dir), "origin.git")})
+				require.NoError(t, err)
+				gittest.Commit(t, clone, map[string]string{"a.txt": "a"}, "a")
+				gittest.Push(t, clone)
+			},
+			checks: all,
+			failed: map[string]string{internal.CHECK_UPSTREAM: "origin/master has commits that are not fetched"},
+		},
+		"no verify": {
+			setup: func(t *testing.T, dir string, origin *git.Repository) {
+				gittest.WriteFiles(t, dir, map[string]string{"readme.md": "changed"})
+			},
+			opts:   internal.Options{NoVerify: true},
+			checks: []string{internal.CHECK_CONFIG, internal.CHECK_REMOTE, internal.CHECK_HOOKS, internal.CHECK_VERSION, internal.CHECK_BRANCH},
+		},
+		"invalid config": {
+			files:  map[string]string{".bump.json": `{"scheme": "semantic"}`},
+			checks: []string{internal.CHECK_CONFIG, internal.CHECK_WORKTREE, internal.CHECK_REMOTE, internal.CHECK_UPSTREAM},
+			failed: map[string]string{internal.CHECK_CONFIG: `scheme must be one of semver, calver, pep440, go, numeric, got "semantic"`},
+		},
+		"missing hooks": {
+			files: map[string]string{
+				".bump.json": `{"preHook": ["./missing.sh", "nosuchprogram --flag", "./readme.md"], "postHook": ["$SCRIPT"]}`,
+			},
+			checks: all,
+			failed: map[string]string{internal.CHECK_HOOKS: "pre-hook \"./missing.sh\": ./missing.sh does not exist\n" +
+				"pre-hook \"nosuchprogram --flag\": nosuchprogram not found\n" +
+				"pre-hook \"./readme.md\": ./readme.md is not executable"},
+		},
+		"empty shell": {
+			files:  map[string]string{".bump.json": `{"shell": " ", "preHook": ["true"]}`},
+			checks: all,
+			failed: map[string]string{internal.CHECK_HOOKS: "shell is empty"},
+		},
+		"skipped hooks": {
+			files:  map[string]string{".bump.json": `{"preHook": ["./missing.sh"]}`},
+			opts:   internal.Options{SkipPreHook: true},
+			checks: all,
+		},
+		"unreachable remote": {
+			setup: func(t *testing.T, dir string, origin *git.Repository) {
+				repo, err := git.PlainOpen(dir)
+				require.NoError(t, err)
+				require.NoError(t, repo.DeleteRemote("origin"))
+				_, err = repo.CreateRemote(&config.RemoteConfig{Name: "origin", URLs: []string{filepath.Join(t.TempDir(), "missing.git")}})
+				require.NoError(t, err)
+			},
+			opts:   internal.Options{NoVerify: true},
+			checks: []string{internal.CHECK_CONFIG, internal.CHECK_REMOTE, internal.CHECK_HOOKS, internal.CHECK_VERSION, internal.CHECK_BRANCH},
+			failed: map[string]string{internal.CHECK_REMOTE: "unable to list origin"},
+		},
+		"other remote": {
+			setup: func(t *testing.T, dir string, origin *git.Repository) {
+				gittest.AddRemote(t, dir, "upstream")
+				repo, err := git.PlainOpen(dir)
+				require.NoError(t, err)
+				require.NoError(t, repo.DeleteRemote("origin"))
+			},
+			opts:   internal.Options{Remote: "upstream"},
+			checks: all,
+		},
+		"tag on origin": {
+			setup: func(t *testing.T, dir string, origin *git.Repository) {
+				head, err := origin.Head()
+				require.NoError(t, err)
+				_, err = origin.CreateTag("v1.0.1", head.Hash(), nil)
+				require.NoError(t, err)
+			},
+			checks: all,
+			failed: map[string]string{internal.CHECK_VERSION: "tag v1.0.1 already exists on origin"},
+		},
+		"push not allowed": {
+			files:  map[string]string{".bump.json": `{"branches": {"master": {"push": false}}}`},
+			checks: all,
+			failed: map[string]string{internal.CHECK_BRANCH: "pushing from branch master is not allowed by the config, see push"},
+		},
+		"via branch without commit": {
+			opts:   internal.Options{ViaBranch: true, NoCommit: true},
+			checks: all,
+			failed: map[string]string{internal.CHECK_BRANCH: "--via-branch can not be used with --no-commit"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			if tc.files == nil {
+				tc.files = map[string]string{}
+			}
+			dir, origin := gittest.NewRepo(t, tc.files)
+			if tc.setup != nil {
+				tc.setup(t, dir, origin)
+			}
+			tc.opts.Dir = dir
+			tc.opts.Getenv = gittest.Getenv(t)
+
+			results, err := internal.CheckRelease(t.Context(), tc.opts)
+			require.NoError(t, err)
+
+			checks := []string{}
+			for _, result := range results {
+				checks = append(checks, result.Name)
+				want, fail := tc.failed[result.Name]
+				if !fail {
+					assert.NoError(t, result.Err, result.Name)
+					continue
+				}
+				assert.ErrorContains(t, result.Err, want, result.Name)
+			}
+			assert.Equal(t, tc.checks, checks)
+			if tc.version != "" {
+				assert.Equal(t, tc.version, results[5].Detail)
+			}
+
+			// nothing was tagged
+			_, err = origin.Tag("v1.0.1")
+			if name != "tag on origin" {
+				assert.ErrorIs(t, err, git.ErrTagNotFound)
+			}
+		})
+	}
+}
+
+func TestWriteCheckReport(t *testing.T) {
+	b := &strings.Builder{}
+	err := internal.WriteCheckReport(b, []internal.CheckResult{
+		{Name: internal.CHECK_CONFIG, Detail: "scheme semver"},
+		{Name: internal.CHECK_WORKTREE, Err: errors.New("uncommitted changes:\n M readme.md")},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "ok    config    scheme semver\nFAIL  worktree  uncommitted changes:\n                 M readme.md\n", b.String())
+}
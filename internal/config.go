@@ -0,0 +1,158 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+
+	"io"
+	"os"
+)
+
+const (
+	CONFIG_FILE      = ".bump.json"
+	CONFIG_FILE_YAML = ".bump.yaml"
+	CONFIG_FILE_YML  = ".bump.yml"
+	CONFIG_FILE_TOML = ".bump.toml"
+)
+
+// CONFIG_FILES are the supported config files in order of precedence. Only one may exist.
+var CONFIG_FILES = []string{CONFIG_FILE, CONFIG_FILE_YAML, CONFIG_FILE_YML, CONFIG_FILE_TOML}
+
+// Config is the config file. The json names are used for every format, and the
+// description, default, enum and pattern tags generate bump.schema.json.
+type Config struct {
+	Schema        *string            `json:"$schema" description:"URL of the JSON schema of the config"`
+	Extends       *string            `json:"extends" description:"Path of a config to extend, relative to this file. A directory extends the config file in it. Fields set in this file override the extended config"`
+	Commit        *bool              `json:"commit" description:"Whether to commit changes from the preHook" default:"true"`
+	Message       *string            `json:"message" description:"The commit message template to use when bumping the version" default:"release {{.Version}}"`
+	TagMessage    *string            `json:"tagMessage" description:"Message template for an annotated tag. A lightweight tag is created when not set"`
+	Author        *Identity          `json:"author" description:"Author of the release commit. Defaults to the git config"`
+	Committer     *Identity          `json:"committer" description:"Committer of the release commit and tagger of annotated tags. Defaults to the git config or the author"`
+	SignOff       *bool              `json:"signOff" description:"Whether to add a Signed-off-by trailer for the author to the release commit" default:"false"`
+	CoAuthors     []string           `json:"coAuthors" description:"Co-authors added as Co-authored-by trailers to the release commit" items:"Co-author in the format 'Name <email>'"`
+	ViaBranch     *bool              `json:"viaBranch" description:"Whether to push the release commit to a release branch instead of tagging. Tag the merged release with bump finalize" default:"false"`
+	ReleaseBranch *string            `json:"releaseBranch" description:"Branch name template for the release branch used with viaBranch" default:"release/{{.Version}}"`
+	Scheme        *string            `json:"scheme" description:"The versioning scheme of the tags" enum:"semver,calver,pep440,go,numeric" default:"semver"`
+	Format        *string            `json:"format" description:"Format of the versioning scheme, e.g. YYYY.0M.MICRO for calver or release/{major}.{minor}.{patch}.{build} for numeric"`
+	Channels      []string           `json:"channels" description:"Pre-release channels, lowest first. bump promote moves a pre-release to the next channel" pattern:"^[0-9A-Za-z-]*[A-Za-z-][0-9A-Za-z-]*$" default:"alpha,beta,rc"`
+	Channel       *string            `json:"channel" description:"Pre-release channel of every bump, like --channel. Set it per branch, e.g. beta on develop and an empty string for finals on main" pattern:"^([0-9A-Za-z-]*[A-Za-z-][0-9A-Za-z-]*)?$"`
+	Strict        *bool              `json:"strict" description:"Reject tags and versions that do not follow the SemVer spec exactly, e.g. leading zeros or invalid build metadata" default:"false"`
+	GoModule      *string            `json:"goModule" description:"What to do when a major bump does not match the /vN suffix of the Go module path. Defaults to error for the go scheme and warn otherwise" enum:"ignore,warn,error,rewrite"`
+	Prefix        *string            `json:"prefix" description:"The prefix to use when bumping the version, e.g. v"`
+	Fetch         *bool              `json:"fetch" description:"Whether to fetch the latest tags before bumping the version" default:"true"`
+	Verify        *bool              `json:"verify" description:"Whether to verify the repository is clean before bumping the version" default:"true"`
+	Remote        *string            `json:"remote" description:"Name of the git remote to fetch from and push to" default:"origin"`
+	Push          *bool              `json:"push" description:"Whether releases may be pushed. bump refuses to release when false, e.g. from branches that are not released" default:"true"`
+	Debug         *bool              `json:"debug" description:"Whether to print debug information" default:"false"`
+	Quiet         *bool              `json:"quiet" description:"Whether to only print errors" default:"false"`
+	Shell         *string            `json:"shell" description:"The shell to use when running the hooks" default:"/bin/bash -c"`
+	PreHook       []string           `json:"preHook" description:"List of commands to run before bumping the version. Version is available as ${VERSION}" items:"Command to run before bumping the version"`
+	PostHook      []string           `json:"postHook" description:"List of commands to run after the tag has been pushed. Version and pre-hook outputs are available as environment variables" items:"Command to run after the tag has been pushed"`
+	Branches      map[string]*Config `json:"branches" description:"Config overrides for branches matching a glob pattern, e.g. release/*. When more than one pattern matches, the longest pattern wins"`
+}
+
+// ReadConfig reads the config file of the repository. JSON may have comments and
+// trailing commas. Nil is returned if there is no config file. The user config and
+// extended configs are not read, see LoadConfig.
+func ReadConfig(fsys fs.FS) (*Config, error) {
+	name, err := findConfigFile(fsys, CONFIG_FILES)
+	if err != nil || name == "" {
+		return nil, err
+	}
+
+	file, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close() // nolint:errcheck
+
+	bytes, err := io.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := parseConfig(name, bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	setDefaults(config)
+
+	return config, nil
+}
+
+// findConfigFile returns the name of the config file, or an empty string if there is none
+func findConfigFile(fsys fs.FS, names []string) (string, error) {
+	found := []string{}
+	for _, name := range names {
+		_, err := fs.Stat(fsys, name)
+		if err == nil {
+			found = append(found, name)
+		} else if !os.IsNotExist(err) {
+			return "", err
+		}
+	}
+
+	switch len(found) {
+	case 0:
+		return "", nil
+	case 1:
+		return found[0], nil
+	}
+	return "", fmt.Errorf("more than one config file: %s, keep only one", SliceString(found))
+}
+
+// parseConfig parses a config by file extension and validates it against Config, so
+// unknown fields and wrong types are reported with their position. The json field
+// names are used for every format.
+func parseConfig(name string, data []byte) (*Config, error) {
+	node, err := parseConfigNode(name, data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	if err = validateConfig(name, node); err != nil {
+		return nil, err
+	}
+
+	config, err := decodeConfig(node)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return config, nil
+}
+
+// decodeConfig decodes a validated config node
+func decodeConfig(node *configNode) (*Config, error) {
+	data, err := json.Marshal(node.Any())
+	if err != nil {
+		return nil, err
+	}
+	var config Config
+	if err = json.Unmarshal(data, &config); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// DefaultConfig returns the config used when there is no config file
+func DefaultConfig() *Config {
+	config := &Config{}
+	setDefaults(config)
+	return config
+}
+
+func setDefaults(config *Config) {
+	if config.Message == nil {
+		config.Message = new("release {{.Version}}")
+	}
+	if config.ReleaseBranch == nil || *config.ReleaseBranch == "" {
+		config.ReleaseBranch = new("release/{{.Version}}")
+	}
+	if config.Remote == nil || *config.Remote == "" {
+		config.Remote = new(DEFAULT_REMOTE)
+	}
+	if config.Shell == nil || *config.Shell == "" {
+		config.Shell = new("/bin/bash -c")
+	}
+}
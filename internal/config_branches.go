@@ -0,0 +1,91 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// BRANCH_EXCLUDED are the config fields that can not be overridden per branch
+var BRANCH_EXCLUDED = []string{"$schema", "extends", "branches"}
+
+func validBranchPattern(pattern string) error {
+	_, err := path.Match(pattern, "")
+	return err
+}
+
+// BranchPatterns returns the patterns of the branch overrides matching the branch, in
+// order of precedence, lowest first. Longer patterns take precedence, so an exact
+// branch name overrides a wildcard.
+func BranchPatterns(branches map[string]*Config, branch string) []string {
+	patterns := []string{}
+	if branch == "" {
+		return patterns
+	}
+	for pattern := range branches {
+		if ok, _ := path.Match(pattern, branch); ok {
+			patterns = append(patterns, pattern)
+		}
+	}
+	sort.Slice(patterns, func(i, j int) bool {
+		if len(patterns[i]) != len(patterns[j]) {
+			return len(patterns[i]) < len(patterns[j])
+		}
+		return patterns[i] < patterns[j]
+	})
+	return patterns
+}
+
+// branchLayers returns the overrides of the config for the branch as layers. source is
+// where the branches were configured.
+func branchLayers(config *Config, source, branch string) []configLayer {
+	layers := []configLayer{}
+	if config == nil {
+		return layers
+	}
+	for _, pattern := range BranchPatterns(config.Branches, branch) {
+		Debug("using branch config", "pattern", pattern)
+		layers = append(layers, configLayer{
+			Source: fmt.Sprintf("%s branches[%s]", source, pattern),
+			Config: config.Branches[pattern],
+		})
+	}
+	return layers
+}
+
+// currentBranch returns the checked out branch, or an empty string if HEAD is detached
+// or there are no commits yet
+func currentBranch(repo *Repo) (string, error) {
+	branch, err := repo.Branch()
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+	if branch == plumbing.HEAD.String() {
+		return "", nil
+	}
+	return branch, nil
+}
+
+// checkPush refuses to release if the config does not allow pushing, e.g. from the
+// branch. A dry run only prints it.
+func checkPush(rc *releaseContext) error {
+	err := pushAllowed(rc.config, rc.branch)
+	if err != nil && rc.dryRun {
+		rc.log.Info("pushing from the branch is not allowed by the config", "branch", rc.branch)
+		return nil
+	}
+	return err
+}
+
+// pushAllowed returns an error if the config does not allow pushing from the branch
+func pushAllowed(config *Config, branch string) error {
+	if config == nil || config.Push == nil || *config.Push {
+		return nil
+	}
+	return fmt.Errorf("pushing from branch %s is not allowed by the config, see push", branch)
+}
@@ -0,0 +1,79 @@
+package internal_test
+
+import (
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/mrvinkel/bump/internal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBranchPatterns(t *testing.T) {
+	branches := map[string]*internal.Config{
+		"*":           {},
+		"release/*":   {},
+		"release/1.x": {},
+		"main":        {},
+	}
+
+	assert.Equal(t, []string{"*", "main"}, internal.BranchPatterns(branches, "main"))
+	assert.Equal(t, []string{"release/*", "release/1.x"}, internal.BranchPatterns(branches, "release/1.x"))
+	assert.Equal(t, []string{"release/*"}, internal.BranchPatterns(branches, "release/2.x"))
+	// * does not match /
+	assert.Empty(t, internal.BranchPatterns(branches, "feature/x/y"))
+	// detached HEAD
+	assert.Empty(t, internal.BranchPatterns(branches, ""))
+}
+
+func TestResolveConfigBranches(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"repo/.bump.yaml": `prefix: v
+preHook: [make]
+branches:
+  develop:
+    channel: beta
+  "release/*":
+    prefix: release-
+    preHook: [make release]
+  release/1.x:
+    push: false
+`,
+	})
+
+	config, sources, err := internal.ResolveConfig(testCommand(t), filepath.Join(root, "repo"), "develop", getenv(nil))
+	require.NoError(t, err)
+	assert.Equal(t, "beta", *config.Channel)
+	assert.Equal(t, ".bump.yaml branches[develop]", sources["channel"])
+	assert.Equal(t, "v", *config.Prefix)
+
+	config, sources, err = internal.ResolveConfig(testCommand(t), filepath.Join(root, "repo"), "release/1.x", getenv(nil))
+	require.NoError(t, err)
+	assert.Nil(t, config.Channel)
+	assert.Equal(t, "release-", *config.Prefix)
+	assert.Equal(t, []string{"make release"}, config.PreHook)
+	assert.False(t, *config.Push)
+	assert.Equal(t, ".bump.yaml branches[release/1.x]", sources["push"])
+
+	// flags override the branch
+	config, _, err = internal.ResolveConfig(testCommand(t, "--prefix", "x"), filepath.Join(root, "repo"), "release/2.x", getenv(nil))
+	require.NoError(t, err)
+	assert.Equal(t, "x", *config.Prefix)
+	assert.Nil(t, config.Push)
+}
+
+func TestReadConfigBranchesInvalid(t *testing.T) {
+	data := `{
+  "branches": {
+    "main": {"extends": "../base.json", "prefix": 1},
+    "release/[": {}
+  }
+}`
+	_, err := internal.ReadConfig(fstest.MapFS{internal.CONFIG_FILE: &fstest.MapFile{Data: []byte(data)}})
+
+	assert.ErrorContains(t, err, `.bump.json:3:14: unknown field "branches[main].extends"`)
+	assert.ErrorContains(t, err, `.bump.json:3:51: branches[main].prefix must be a string, got number`)
+	assert.ErrorContains(t, err, `.bump.json:4:5: branches has an invalid pattern "release/["`)
+}
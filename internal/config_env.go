@@ -0,0 +1,285 @@
+package internal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"unicode"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+const (
+	ENV_PREFIX = "BUMP_"
+
+	// annotation of flags set from the environment, with the name of the variable
+	ENV_ANNOTATION = "bump_env"
+	// source of fields that are not set
+	SOURCE_DEFAULT = "default"
+)
+
+// configFlags are the flags that set a config field, by flag name. The no- flags set
+// the inverse of the field.
+var configFlags = map[string]string{
+	"debug":      "debug",
+	"quiet":      "quiet",
+	"no-verify":  "verify",
+	"no-fetch":   "fetch",
+	"no-commit":  "commit",
+	"sign-off":   "signOff",
+	"via-branch": "viaBranch",
+	"strict":     "strict",
+	"prefix":     "prefix",
+	"channel":    "channel",
+	"remote":     "remote",
+}
+
+// EnvName returns the environment variable of a flag or config field, e.g. BUMP_NO_FETCH
+// for --no-fetch and BUMP_SIGN_OFF for signOff
+func EnvName(name string) string {
+	b := strings.Builder{}
+	b.WriteString(ENV_PREFIX)
+	for i, r := range name {
+		switch {
+		case r == '-':
+			b.WriteRune('_')
+		case unicode.IsUpper(r) && i > 0:
+			b.WriteRune('_')
+			b.WriteRune(r)
+		default:
+			b.WriteRune(unicode.ToUpper(r))
+		}
+	}
+	return b.String()
+}
+
+// FlagsFromEnv sets the flags that are not given on the command line from their BUMP_*
+// environment variable, e.g. --no-fetch from BUMP_NO_FETCH
+func FlagsFromEnv(flags *pflag.FlagSet, getenv func(string) string) error {
+	errs := []error{}
+	flags.VisitAll(func(f *pflag.Flag) {
+		if f.Changed || f.Name == "help" {
+			return
+		}
+		env := EnvName(f.Name)
+		value := getenv(env)
+		if value == "" {
+			return
+		}
+		if err := flags.Set(f.Name, value); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", env, err))
+			return
+		}
+		if err := flags.SetAnnotation(f.Name, ENV_ANNOTATION, []string{env}); err != nil {
+			errs = append(errs, err)
+		}
+	})
+	return errors.Join(errs...)
+}
+
+// ResolveConfig merges the config files with the overrides for the branch, the
+// environment and the flags. Flags override BUMP_* environment variables, which
+// override the branch overrides, which override the config files, see LoadConfig. The
+// source of each field that is set is returned by json name. Nil is returned if no
+// field is set.
+func ResolveConfig(cmd *cobra.Command, repoDir, branch string, getenv func(string) string) (*Config, map[string]string, error) {
+	flags, err := flagLayers(cmd.Flags())
+	if err != nil {
+		return nil, nil, err
+	}
+	return resolveConfig(repoDir, branch, getenv, flags)
+}
+
+// resolveConfig is ResolveConfig with the overrides of the flags as layers
+func resolveConfig(repoDir, branch string, getenv func(string) string, overrides []configLayer) (*Config, map[string]string, error) {
+	layers, err := configFileLayers(repoDir, getenv)
+	if err != nil {
+		return nil, nil, err
+	}
+	files, sources := mergeLayers(layers)
+	layers = append(layers, branchLayers(files, sources["branches"], branch)...)
+
+	env, err := envLayers(getenv)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	config, sources := mergeLayers(append(append(layers, env...), overrides...))
+	if config != nil {
+		setDefaults(config)
+	}
+	return config, sources, nil
+}
+
+// envLayers returns a layer for each BUMP_* environment variable of a config field.
+// Author and committer are set by BUMP_AUTHOR_NAME and the like, see ResolveIdentities.
+func envLayers(getenv func(string) string) ([]configLayer, error) {
+	layers := []configLayer{}
+	errs := []error{}
+	for _, f := range configFields(reflect.TypeFor[Config]()) {
+		env := EnvName(f.Name)
+		value := getenv(env)
+		if value == "" || f.Name == "$schema" || f.Name == "extends" || f.Type.Kind() == reflect.Map || f.Type.Elem().Kind() == reflect.Struct {
+			continue
+		}
+		config, err := fieldConfig("environment", env, f, envNode(f, value))
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		layers = append(layers, configLayer{Source: "env " + env, Config: config})
+	}
+	return layers, errors.Join(errs...)
+}
+
+// envNode converts an environment variable to a config value. Lists are comma
+// separated, or newline separated if the value has more than one line.
+func envNode(f configField, value string) *configNode {
+	switch f.Type.Kind() {
+	case reflect.Slice:
+		sep := ","
+		if strings.Contains(value, "\n") {
+			sep = "\n"
+		}
+		n := &configNode{Kind: KIND_ARRAY}
+		for _, item := range strings.Split(value, sep) {
+			if item = strings.TrimSpace(item); item != "" {
+				n.Values = append(n.Values, &configNode{Kind: KIND_STRING, Value: item})
+			}
+		}
+		return n
+	case reflect.Pointer:
+		if f.Type.Elem().Kind() == reflect.Bool {
+			if b, err := strconv.ParseBool(value); err == nil {
+				return &configNode{Kind: KIND_BOOLEAN, Value: strconv.FormatBool(b)}
+			}
+		}
+	}
+	return &configNode{Kind: KIND_STRING, Value: value}
+}
+
+// flagLayers returns a layer for each flag of a config field that is set, on the
+// command line or from the environment
+func flagLayers(flags *pflag.FlagSet) ([]configLayer, error) {
+	fields := map[string]configField{}
+	for _, f := range configFields(reflect.TypeFor[Config]()) {
+		fields[f.Name] = f
+	}
+
+	layers := []configLayer{}
+	errs := []error{}
+	flags.VisitAll(func(flag *pflag.Flag) {
+		name, ok := configFlags[flag.Name]
+		if !ok || !flag.Changed {
+			return
+		}
+
+		n := &configNode{Kind: KIND_STRING, Value: flag.Value.String()}
+		if flag.Value.Type() == "bool" {
+			n.Kind = KIND_BOOLEAN
+			if strings.HasPrefix(flag.Name, "no-") {
+				n.Value = strconv.FormatBool(n.Value != "true")
+			}
+		}
+
+		source := "flag --" + flag.Name
+		if env, ok := flag.Annotations[ENV_ANNOTATION]; ok {
+			source = "env " + env[0]
+		}
+		config, err := fieldConfig("flags", "--"+flag.Name, fields[name], n)
+		if err != nil {
+			errs = append(errs, err)
+			return
+		}
+		layers = append(layers, configLayer{Source: source, Config: config})
+	})
+	return layers, errors.Join(errs...)
+}
+
+// fieldConfig returns a config with only the field set, after validating the value
+func fieldConfig(source, name string, f configField, value *configNode) (*Config, error) {
+	if errs := validateField(source, value, f, name); len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return decodeConfig(&configNode{
+		Kind:   KIND_OBJECT,
+		Keys:   []*configNode{{Kind: KIND_STRING, Value: f.Name}},
+		Values: []*configNode{value},
+	})
+}
+
+// ShowConfig writes every config field with its effective value and source. Fields
+// that are not set show their default, if they have one.
+func ShowConfig(w io.Writer, config *Config, sources map[string]string) error {
+	if config == nil {
+		config = &Config{}
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	v := reflect.ValueOf(config).Elem()
+	for _, f := range configFields(reflect.TypeFor[Config]()) {
+		var value any
+		source, ok := sources[f.Name]
+		switch {
+		case ok && f.Type.Kind() == reflect.Map:
+			// the patterns, the overrides of the branch show as the source of the fields they set
+			value = slices.Sorted(maps.Keys(config.Branches))
+		case ok:
+			value = v.Field(f.Index).Interface()
+		case f.Default != "":
+			value = fieldSchema(f).Default
+			source = SOURCE_DEFAULT
+		default:
+			continue
+		}
+
+		b := strings.Builder{}
+		encoder := json.NewEncoder(&b)
+		encoder.SetEscapeHTML(false)
+		if err := encoder.Encode(value); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(tw, "%s\t%s\t%s\n", f.Name, strings.TrimSpace(b.String()), source); err != nil {
+			return err
+		}
+	}
+	return tw.Flush()
+}
+
+// ConfigShow prints the effective config of the repository and where each value came from
+func ConfigShow(cmd *cobra.Command, args []string) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	repo, err := NewRepo(cwd)
+	if err != nil {
+		return err
+	}
+
+	repoDir, err := repo.GetDir()
+	if err != nil {
+		return err
+	}
+
+	branch, err := currentBranch(repo)
+	if err != nil {
+		return err
+	}
+
+	config, sources, err := ResolveConfig(cmd, repoDir, branch, os.Getenv)
+	if err != nil {
+		return err
+	}
+	return ShowConfig(os.Stdout, config, sources)
+}
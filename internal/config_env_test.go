@@ -0,0 +1,117 @@
+package internal_test
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mrvinkel/bump/internal"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvName(t *testing.T) {
+	assert.Equal(t, "BUMP_NO_FETCH", internal.EnvName("no-fetch"))
+	assert.Equal(t, "BUMP_SIGN_OFF", internal.EnvName("signOff"))
+	assert.Equal(t, "BUMP_PREFIX", internal.EnvName("prefix"))
+	assert.Equal(t, "BUMP_PRE_HOOK", internal.EnvName("preHook"))
+}
+
+func testCommand(t *testing.T, args ...string) *cobra.Command {
+	t.Helper()
+	cmd := &cobra.Command{Use: "bump"}
+	cmd.Flags().BoolP("no-fetch", "f", false, "")
+	cmd.Flags().Bool("sign-off", false, "")
+	cmd.Flags().Bool("dry-run", false, "")
+	cmd.Flags().String("prefix", "", "")
+	require.NoError(t, cmd.ParseFlags(args))
+	return cmd
+}
+
+func TestFlagsFromEnv(t *testing.T) {
+	cmd := testCommand(t, "--prefix", "flag-")
+
+	err := internal.FlagsFromEnv(cmd.Flags(), getenv(map[string]string{
+		"BUMP_PREFIX":   "env-",
+		"BUMP_DRY_RUN":  "true",
+		"BUMP_NO_FETCH": "1",
+	}))
+	require.NoError(t, err)
+
+	prefix, _ := cmd.Flags().GetString("prefix")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	noFetch, _ := cmd.Flags().GetBool("no-fetch")
+	assert.Equal(t, "flag-", prefix)
+	assert.True(t, dryRun)
+	assert.True(t, noFetch)
+
+	err = internal.FlagsFromEnv(testCommand(t).Flags(), getenv(map[string]string{"BUMP_DRY_RUN": "yes"}))
+	assert.ErrorContains(t, err, "BUMP_DRY_RUN: invalid argument")
+}
+
+func TestResolveConfig(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"repo/.bump.json": `{"prefix": "repo-", "fetch": true, "signOff": false, "scheme": "semver", "channels": ["rc"]}`,
+	})
+	env := getenv(map[string]string{
+		"BUMP_FETCH":    "false",
+		"BUMP_SCHEME":   "calver",
+		"BUMP_PREFIX":   "env-",
+		"BUMP_CHANNELS": "alpha, beta",
+		"BUMP_PRE_HOOK": "echo a,b\nmake",
+	})
+	cmd := testCommand(t, "--prefix", "flag-", "--sign-off")
+	require.NoError(t, internal.FlagsFromEnv(cmd.Flags(), env))
+
+	config, sources, err := internal.ResolveConfig(cmd, filepath.Join(root, "repo"), "", env)
+	require.NoError(t, err)
+
+	// flags override env, which overrides the config file
+	assert.Equal(t, "flag-", *config.Prefix)
+	assert.Equal(t, "flag --prefix", sources["prefix"])
+	assert.True(t, *config.SignOff)
+	assert.Equal(t, "flag --sign-off", sources["signOff"])
+	assert.False(t, *config.Fetch)
+	assert.Equal(t, "env BUMP_FETCH", sources["fetch"])
+	assert.Equal(t, "calver", *config.Scheme)
+	assert.Equal(t, []string{"alpha", "beta"}, config.Channels)
+	assert.Equal(t, []string{"echo a,b", "make"}, config.PreHook)
+
+	// a flag set from the environment
+	cmd = testCommand(t)
+	require.NoError(t, internal.FlagsFromEnv(cmd.Flags(), getenv(map[string]string{"BUMP_NO_FETCH": "true"})))
+	config, sources, err = internal.ResolveConfig(cmd, filepath.Join(root, "repo"), "", getenv(nil))
+	require.NoError(t, err)
+	assert.False(t, *config.Fetch)
+	assert.Equal(t, "env BUMP_NO_FETCH", sources["fetch"])
+	assert.Equal(t, "repo-", *config.Prefix)
+	assert.Equal(t, ".bump.json", sources["prefix"])
+}
+
+func TestResolveConfigInvalidEnv(t *testing.T) {
+	env := getenv(map[string]string{"BUMP_SCHEME": "semantic", "BUMP_VERIFY": "nope"})
+
+	_, _, err := internal.ResolveConfig(testCommand(t), t.TempDir(), "", env)
+	assert.ErrorContains(t, err, `environment: BUMP_SCHEME must be one of semver, calver, pep440, go, numeric, got "semantic"`)
+	assert.ErrorContains(t, err, "environment: BUMP_VERIFY must be a boolean, got string")
+}
+
+func TestShowConfig(t *testing.T) {
+	config := &internal.Config{
+		Prefix:    new("v"),
+		CoAuthors: []string{"Jane Doe <jane@example.com>"},
+		Message:   new("release {{.Version}}"),
+	}
+	sources := map[string]string{"prefix": "flag --prefix", "coAuthors": ".bump.json"}
+
+	b := strings.Builder{}
+	require.NoError(t, internal.ShowConfig(&b, config, sources))
+
+	lines := strings.Split(b.String(), "\n")
+	assert.Contains(t, lines, `coAuthors      ["Jane Doe <jane@example.com>"]  .bump.json`)
+	assert.Contains(t, lines, `prefix         "v"                              flag --prefix`)
+	assert.Contains(t, lines, `message        "release {{.Version}}"           default`)
+	assert.NotContains(t, b.String(), "tagMessage")
+}
@@ -0,0 +1,182 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+)
+
+const (
+	XDG_CONFIG_HOME_ENV = "XDG_CONFIG_HOME"
+	USER_CONFIG_DIR     = "bump"
+)
+
+// USER_CONFIG_FILES are the supported user config files in the user config directory.
+// Only one may exist.
+var USER_CONFIG_FILES = []string{"config.json", "config.yaml", "config.yml", "config.toml"}
+
+// UserConfigDir returns $XDG_CONFIG_HOME/bump, or ~/.config/bump if XDG_CONFIG_HOME is
+// not set. An empty string is returned if neither is known.
+func UserConfigDir(getenv func(string) string) string {
+	if dir := getenv(XDG_CONFIG_HOME_ENV); dir != "" {
+		return filepath.Join(dir, USER_CONFIG_DIR)
+	}
+	if home := getenv("HOME"); home != "" {
+		return filepath.Join(home, ".config", USER_CONFIG_DIR)
+	}
+	return ""
+}
+
+// configLayer is a config and where it came from, a file, an environment variable or a
+// flag. Layers are merged lowest precedence first.
+type configLayer struct {
+	Source string
+	Config *Config
+}
+
+// LoadConfig reads the user config, the repository config and the configs they extend,
+// and merges them. The repository config overrides the configs it extends, which
+// override the user config. Nil is returned if there is no config file.
+func LoadConfig(repoDir string, getenv func(string) string) (*Config, error) {
+	layers, err := configFileLayers(repoDir, getenv)
+	if err != nil {
+		return nil, err
+	}
+
+	config, _ := mergeLayers(layers)
+	if config == nil {
+		return nil, nil
+	}
+	setDefaults(config)
+	return config, nil
+}
+
+// configFileLayers reads the user config, the repository config and the configs they
+// extend, lowest precedence first
+func configFileLayers(repoDir string, getenv func(string) string) ([]configLayer, error) {
+	layers := []configLayer{}
+	if dir := UserConfigDir(getenv); dir != "" {
+		name, err := findConfigFile(os.DirFS(dir), USER_CONFIG_FILES)
+		if err != nil {
+			return nil, err
+		}
+		if name != "" {
+			user, err := readConfigFile("", filepath.Join(dir, name), map[string]bool{})
+			if err != nil {
+				return nil, err
+			}
+			layers = append(layers, user...)
+		}
+	}
+
+	name, err := findConfigFile(os.DirFS(repoDir), CONFIG_FILES)
+	if err != nil {
+		return nil, err
+	}
+	if name != "" {
+		repo, err := readConfigFile(repoDir, name, map[string]bool{})
+		if err != nil {
+			return nil, err
+		}
+		layers = append(layers, repo...)
+	}
+	return layers, nil
+}
+
+// readConfigFile reads a config and the configs it extends, extended configs first. A
+// relative name is read from dir, and is the name used in errors.
+func readConfigFile(dir, name string, seen map[string]bool) ([]configLayer, error) {
+	path := name
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, name)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		file, err := findConfigFile(os.DirFS(path), CONFIG_FILES)
+		if err != nil {
+			return nil, err
+		}
+		if file == "" {
+			return nil, fmt.Errorf("no config file in %s", name)
+		}
+		name = filepath.Join(name, file)
+		path = filepath.Join(path, file)
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	if seen[abs] {
+		return nil, fmt.Errorf("%s: extends form a cycle", name)
+	}
+	seen[abs] = true
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	config, err := parseConfig(name, data)
+	if err != nil {
+		return nil, err
+	}
+	layer := configLayer{Source: name, Config: config}
+	if config.Extends == nil || *config.Extends == "" {
+		return []configLayer{layer}, nil
+	}
+
+	extends := *config.Extends
+	if !filepath.IsAbs(extends) {
+		extends = filepath.Join(filepath.Dir(name), extends)
+	}
+	Debug("config extends", "file", name, "extends", extends)
+	layers, err := readConfigFile(dir, extends, seen)
+	if err != nil {
+		return nil, err
+	}
+	return append(layers, layer), nil
+}
+
+// mergeLayers merges the layers and returns the source of each field that is set, by
+// json name. Nil is returned if there are no layers.
+func mergeLayers(layers []configLayer) (*Config, map[string]string) {
+	var config *Config
+	sources := map[string]string{}
+	fields := configFields(reflect.TypeFor[Config]())
+	for _, layer := range layers {
+		config = mergeConfig(config, layer.Config)
+		v := reflect.ValueOf(layer.Config).Elem()
+		for _, f := range fields {
+			if !v.Field(f.Index).IsNil() {
+				sources[f.Name] = layer.Source
+			}
+		}
+	}
+	return config, sources
+}
+
+// mergeConfig returns base with the fields set in override replaced. Lists and
+// identities are replaced as a whole. Either may be nil.
+func mergeConfig(base, override *Config) *Config {
+	if base == nil {
+		return override
+	}
+	if override == nil {
+		return base
+	}
+
+	merged := *base
+	m := reflect.ValueOf(&merged).Elem()
+	o := reflect.ValueOf(override).Elem()
+	for i := range o.NumField() {
+		if !o.Field(i).IsNil() {
+			m.Field(i).Set(o.Field(i))
+		}
+	}
+	return &merged
+}
@@ -0,0 +1,129 @@
+package internal_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mrvinkel/bump/internal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(data), 0o644))
+	}
+}
+
+func getenv(env map[string]string) func(string) string {
+	return func(key string) string {
+		return env[key]
+	}
+}
+
+func TestUserConfigDir(t *testing.T) {
+	assert.Equal(t, filepath.Join("/xdg", "bump"), internal.UserConfigDir(getenv(map[string]string{"XDG_CONFIG_HOME": "/xdg", "HOME": "/home/me"})))
+	assert.Equal(t, filepath.Join("/home/me", ".config", "bump"), internal.UserConfigDir(getenv(map[string]string{"HOME": "/home/me"})))
+	assert.Equal(t, "", internal.UserConfigDir(getenv(nil)))
+}
+
+func TestLoadConfig(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"xdg/bump/config.yaml": "quiet: true\nsignOff: true\nprefix: user-\nshell: /bin/sh -c\n",
+		"platform/bump.json":   `{"extends": "base.toml", "prefix": "v", "preHook": ["make"]}`,
+		"platform/base.toml":   "fetch = false\nprefix = \"base-\"\n",
+		"repo/.bump.json":      `{"extends": "../platform/bump.json", "preHook": ["make test"]}`,
+	})
+
+	config, err := internal.LoadConfig(filepath.Join(root, "repo"), getenv(map[string]string{"XDG_CONFIG_HOME": filepath.Join(root, "xdg")}))
+	require.NoError(t, err)
+
+	// user
+	assert.True(t, *config.Quiet)
+	assert.True(t, *config.SignOff)
+	assert.Equal(t, "/bin/sh -c", *config.Shell)
+	// extends of extends
+	assert.False(t, *config.Fetch)
+	// extends overrides its base and the user config
+	assert.Equal(t, "v", *config.Prefix)
+	// repo overrides extends, lists are replaced
+	assert.Equal(t, []string{"make test"}, config.PreHook)
+	// defaults
+	assert.Equal(t, "release {{.Version}}", *config.Message)
+}
+
+func TestLoadConfigExtendsDirectory(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"shared/.bump.yaml": "prefix: v\n",
+		"repo/.bump.json":   `{"extends": "../shared"}`,
+	})
+
+	config, err := internal.LoadConfig(filepath.Join(root, "repo"), getenv(nil))
+	require.NoError(t, err)
+	assert.Equal(t, "v", *config.Prefix)
+}
+
+func TestLoadConfigNotExist(t *testing.T) {
+	config, err := internal.LoadConfig(t.TempDir(), getenv(map[string]string{"XDG_CONFIG_HOME": t.TempDir()}))
+
+	assert.NoError(t, err)
+	assert.Nil(t, config)
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	type test struct {
+		name  string
+		files map[string]string
+		err   string
+	}
+
+	tests := []test{
+		{
+			name: "cycle",
+			files: map[string]string{
+				"repo/.bump.json": `{"extends": "../a.json"}`,
+				"a.json":          `{"extends": "b.json"}`,
+				"b.json":          `{"extends": "a.json"}`,
+			},
+			err: "a.json: extends form a cycle",
+		},
+		{
+			name: "invalid extended config",
+			files: map[string]string{
+				"repo/.bump.json": `{"extends": "../a.yaml"}`,
+				"a.yaml":          "prefixes: v\n",
+			},
+			err: `../a.yaml:1:1: unknown field "prefixes", did you mean "prefix"?`,
+		},
+		{
+			name: "missing extended config",
+			files: map[string]string{
+				"repo/.bump.json": `{"extends": "../a.json"}`,
+			},
+			err: "no such file or directory",
+		},
+		{
+			name: "directory without config",
+			files: map[string]string{
+				"repo/.bump.json": `{"extends": ".."}`,
+			},
+			err: "no config file in ..",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			root := t.TempDir()
+			writeFiles(t, root, tc.files)
+
+			_, err := internal.LoadConfig(filepath.Join(root, "repo"), getenv(nil))
+			assert.ErrorContains(t, err, tc.err)
+		})
+	}
+}
@@ -0,0 +1,260 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/tailscale/hujson"
+	"go.yaml.in/yaml/v3"
+)
+
+// kinds of config values, named like the JSON schema types
+const (
+	KIND_OBJECT  = "object"
+	KIND_ARRAY   = "array"
+	KIND_STRING  = "string"
+	KIND_BOOLEAN = "boolean"
+	KIND_NUMBER  = "number"
+	KIND_NULL    = "null"
+	// TOML dates, which are not valid for any config field
+	KIND_DATETIME = "datetime"
+)
+
+// configNode is a value of a config file with its position, so errors can point to
+// the line and column of the problem. Line is 0 if the position is unknown.
+type configNode struct {
+	Kind   string
+	Line   int
+	Column int
+	// Value of a scalar
+	Value string
+	// Keys of an object, with the position of the key
+	Keys []*configNode
+	// Values of an object by key index, or the elements of an array
+	Values []*configNode
+}
+
+// Position returns the position as file:line:column, or just the file if unknown
+func (n *configNode) Position(file string) string {
+	if n.Line == 0 {
+		return file
+	}
+	return fmt.Sprintf("%s:%d:%d", file, n.Line, n.Column)
+}
+
+// Any returns the value as maps, slices and scalars for encoding/json
+func (n *configNode) Any() any {
+	switch n.Kind {
+	case KIND_OBJECT:
+		m := map[string]any{}
+		for i, key := range n.Keys {
+			m[key.Value] = n.Values[i].Any()
+		}
+		return m
+	case KIND_ARRAY:
+		a := []any{}
+		for _, v := range n.Values {
+			a = append(a, v.Any())
+		}
+		return a
+	case KIND_BOOLEAN:
+		return strings.EqualFold(n.Value, "true")
+	case KIND_NUMBER:
+		return json.Number(n.Value)
+	case KIND_NULL:
+		return nil
+	}
+	return n.Value
+}
+
+// parseConfigNode parses a config file by extension
+func parseConfigNode(name string, data []byte) (*configNode, error) {
+	switch {
+	case strings.HasSuffix(name, ".json"):
+		return jsonNode(data)
+	case strings.HasSuffix(name, ".yaml"), strings.HasSuffix(name, ".yml"):
+		return yamlNode(data)
+	case strings.HasSuffix(name, ".toml"):
+		return tomlNode(data)
+	}
+	return nil, fmt.Errorf("unsupported config file %s", name)
+}
+
+// jsonNode parses JSON with comments and trailing commas
+func jsonNode(data []byte) (*configNode, error) {
+	v, err := hujson.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	return hujsonNode(data, v), nil
+}
+
+func hujsonNode(data []byte, v hujson.Value) *configNode {
+	n := &configNode{}
+	n.Line, n.Column = lineColumn(data, v.StartOffset)
+
+	switch value := v.Value.(type) {
+	case *hujson.Object:
+		n.Kind = KIND_OBJECT
+		for _, m := range value.Members {
+			n.Keys = append(n.Keys, hujsonNode(data, m.Name))
+			n.Values = append(n.Values, hujsonNode(data, m.Value))
+		}
+	case *hujson.Array:
+		n.Kind = KIND_ARRAY
+		for _, e := range value.Elements {
+			n.Values = append(n.Values, hujsonNode(data, e))
+		}
+	case hujson.Literal:
+		n.Value = value.String()
+		switch value.Kind() {
+		case '"':
+			n.Kind = KIND_STRING
+		case 't', 'f':
+			n.Kind = KIND_BOOLEAN
+		case '0':
+			n.Kind = KIND_NUMBER
+		default:
+			n.Kind = KIND_NULL
+		}
+	}
+	return n
+}
+
+func lineColumn(data []byte, offset int) (int, int) {
+	line := 1 + bytes.Count(data[:offset], []byte("\n"))
+	column := 1 + offset - (bytes.LastIndexByte(data[:offset], '\n') + 1)
+	return line, column
+}
+
+func yamlNode(data []byte) (*configNode, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		// empty file
+		return &configNode{Kind: KIND_OBJECT, Line: 1, Column: 1}, nil
+	}
+	return yamlValueNode(doc.Content[0]), nil
+}
+
+func yamlValueNode(y *yaml.Node) *configNode {
+	if y.Kind == yaml.AliasNode {
+		return yamlValueNode(y.Alias)
+	}
+
+	n := &configNode{Line: y.Line, Column: y.Column, Value: y.Value}
+	switch y.Kind {
+	case yaml.MappingNode:
+		n.Kind = KIND_OBJECT
+		for i := 0; i+1 < len(y.Content); i += 2 {
+			n.Keys = append(n.Keys, yamlValueNode(y.Content[i]))
+			n.Values = append(n.Values, yamlValueNode(y.Content[i+1]))
+		}
+	case yaml.SequenceNode:
+		n.Kind = KIND_ARRAY
+		for _, e := range y.Content {
+			n.Values = append(n.Values, yamlValueNode(e))
+		}
+	default:
+		switch y.ShortTag() {
+		case "!!bool":
+			n.Kind = KIND_BOOLEAN
+		case "!!int", "!!float":
+			n.Kind = KIND_NUMBER
+		case "!!null":
+			n.Kind = KIND_NULL
+		default:
+			n.Kind = KIND_STRING
+		}
+	}
+	return n
+}
+
+var (
+	tomlTable = regexp.MustCompile(`^\s*\[\[?\s*([^\]]+?)\s*\]\]?`)
+	tomlKey   = regexp.MustCompile(`^\s*("[^"]*"|'[^']*'|[A-Za-z0-9_$-]+)\s*=`)
+)
+
+func tomlNode(data []byte) (*configNode, error) {
+	var m map[string]any
+	if _, err := toml.Decode(string(data), &m); err != nil {
+		return nil, err
+	}
+	return tomlValueNode(m, nil, tomlKeyLines(data)), nil
+}
+
+// tomlKeyLines finds the line of each key, by dotted path. The TOML decoder does not
+// report positions, so this only covers keys and tables on their own line.
+func tomlKeyLines(data []byte) map[string]int {
+	lines := map[string]int{}
+	table := ""
+	for i, line := range strings.Split(string(data), "\n") {
+		if m := tomlTable.FindStringSubmatch(line); m != nil {
+			table = m[1]
+			if _, ok := lines[table]; !ok {
+				lines[table] = i + 1
+			}
+		} else if m := tomlKey.FindStringSubmatch(line); m != nil {
+			key := strings.Trim(m[1], `"'`)
+			if table != "" {
+				key = table + "." + key
+			}
+			lines[key] = i + 1
+		}
+	}
+	return lines
+}
+
+func tomlValueNode(v any, path []string, lines map[string]int) *configNode {
+	n := &configNode{Line: lines[strings.Join(path, ".")]}
+	if n.Line != 0 {
+		n.Column = 1
+	}
+
+	switch value := v.(type) {
+	case map[string]any:
+		n.Kind = KIND_OBJECT
+		keys := make([]string, 0, len(value))
+		for key := range value {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			keyPath := append(append([]string{}, path...), key)
+			child := tomlValueNode(value[key], keyPath, lines)
+			n.Keys = append(n.Keys, &configNode{Kind: KIND_STRING, Value: key, Line: child.Line, Column: child.Column})
+			n.Values = append(n.Values, child)
+		}
+	case []map[string]any:
+		n.Kind = KIND_ARRAY
+		for _, e := range value {
+			n.Values = append(n.Values, tomlValueNode(e, path, lines))
+		}
+	case []any:
+		n.Kind = KIND_ARRAY
+		for _, e := range value {
+			n.Values = append(n.Values, tomlValueNode(e, path, lines))
+		}
+	case bool:
+		n.Kind = KIND_BOOLEAN
+		n.Value = fmt.Sprint(value)
+	case int64, float64:
+		n.Kind = KIND_NUMBER
+		n.Value = fmt.Sprint(value)
+	case time.Time:
+		n.Kind = KIND_DATETIME
+		n.Value = value.Format(time.RFC3339)
+	default:
+		n.Kind = KIND_STRING
+		n.Value = fmt.Sprint(value)
+	}
+	return n
+}
@@ -0,0 +1,319 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	SCHEMA_DRAFT = "http://json-schema.org/draft-07/schema#"
+	SCHEMA_ID    = "https://github.com/mrvinkel/bump"
+)
+
+// configField is a field of a config struct with the metadata from its tags
+type configField struct {
+	// Index of the field in the struct
+	Index       int
+	Name        string
+	Type        reflect.Type
+	Description string
+	Default     string
+	Enum        []string
+	Pattern     string
+	// Items is the description of the elements of a list
+	Items string
+}
+
+// configFields returns the fields of a config struct in declaration order
+func configFields(t reflect.Type) []configField {
+	fields := []configField{}
+	for i := range t.NumField() {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		field := configField{
+			Index:       i,
+			Name:        name,
+			Type:        f.Type,
+			Description: f.Tag.Get("description"),
+			Default:     f.Tag.Get("default"),
+			Pattern:     f.Tag.Get("pattern"),
+			Items:       f.Tag.Get("items"),
+		}
+		if enum := f.Tag.Get("enum"); enum != "" {
+			field.Enum = strings.Split(enum, ",")
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+// jsonSchema is the subset of JSON schema used for the config
+type jsonSchema struct {
+	Schema               string            `json:"$schema,omitempty"`
+	ID                   string            `json:"$id,omitempty"`
+	Title                string            `json:"title,omitempty"`
+	Type                 string            `json:"type"`
+	Description          string            `json:"description,omitempty"`
+	Enum                 []string          `json:"enum,omitempty"`
+	Pattern              string            `json:"pattern,omitempty"`
+	Default              any               `json:"default,omitempty"`
+	Items                *jsonSchema       `json:"items,omitempty"`
+	Properties           *schemaProperties `json:"properties,omitempty"`
+	AdditionalProperties any               `json:"additionalProperties,omitempty"`
+}
+
+// schemaProperties keeps the properties in field order
+type schemaProperties struct {
+	names   []string
+	schemas []*jsonSchema
+}
+
+func (p *schemaProperties) MarshalJSON() ([]byte, error) {
+	b := bytes.Buffer{}
+	b.WriteString("{")
+	for i, name := range p.names {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		key, err := json.Marshal(name)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(p.schemas[i])
+		if err != nil {
+			return nil, err
+		}
+		b.Write(key)
+		b.WriteString(":")
+		b.Write(value)
+	}
+	b.WriteString("}")
+	return b.Bytes(), nil
+}
+
+// GenerateSchema returns the JSON schema of the config, bump.schema.json
+func GenerateSchema() ([]byte, error) {
+	schema := objectSchema(reflect.TypeFor[Config]())
+	schema.Schema = SCHEMA_DRAFT
+	schema.ID = SCHEMA_ID
+	schema.Title = "Bump config schema"
+	schema.Description = "Defines the fields for " + SliceString(CONFIG_FILES)
+
+	b := bytes.Buffer{}
+	encoder := json.NewEncoder(&b)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(schema); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// objectSchema returns the schema of a struct without the excluded fields
+func objectSchema(t reflect.Type, exclude ...string) *jsonSchema {
+	schema := &jsonSchema{
+		Type:                 KIND_OBJECT,
+		Properties:           &schemaProperties{},
+		AdditionalProperties: new(false),
+	}
+	for _, f := range configFields(t) {
+		if slices.Contains(exclude, f.Name) {
+			continue
+		}
+		schema.Properties.names = append(schema.Properties.names, f.Name)
+		schema.Properties.schemas = append(schema.Properties.schemas, fieldSchema(f))
+	}
+	return schema
+}
+
+func fieldSchema(f configField) *jsonSchema {
+	t := f.Type
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	var schema *jsonSchema
+	switch t.Kind() {
+	case reflect.Struct:
+		schema = objectSchema(t)
+	case reflect.Map:
+		// branch overrides, which can not be nested
+		schema = &jsonSchema{
+			Type:                 KIND_OBJECT,
+			AdditionalProperties: objectSchema(t.Elem().Elem(), BRANCH_EXCLUDED...),
+		}
+	case reflect.Slice:
+		schema = &jsonSchema{
+			Type:  KIND_ARRAY,
+			Items: &jsonSchema{Type: KIND_STRING, Description: f.Items, Pattern: f.Pattern},
+		}
+		if f.Default != "" {
+			schema.Default = strings.Split(f.Default, ",")
+		}
+	case reflect.Bool:
+		schema = &jsonSchema{Type: KIND_BOOLEAN}
+		if f.Default != "" {
+			schema.Default, _ = strconv.ParseBool(f.Default)
+		}
+	default:
+		schema = &jsonSchema{Type: KIND_STRING, Enum: f.Enum, Pattern: f.Pattern}
+		if f.Default != "" {
+			schema.Default = f.Default
+		}
+	}
+	schema.Description = f.Description
+	return schema
+}
+
+// validateConfig checks a parsed config file against the Config struct. Unknown fields,
+// wrong types, values not in the enum and values not matching the pattern are reported
+// with their position in the file.
+func validateConfig(file string, n *configNode) error {
+	return errors.Join(validateObject(file, n, reflect.TypeFor[Config](), "")...)
+}
+
+func validateObject(file string, n *configNode, t reflect.Type, path string, exclude ...string) []error {
+	if n.Kind != KIND_OBJECT {
+		return []error{typeError(file, n, path, KIND_OBJECT)}
+	}
+
+	fields := []configField{}
+	names := []string{}
+	for _, f := range configFields(t) {
+		if !slices.Contains(exclude, f.Name) {
+			fields = append(fields, f)
+			names = append(names, f.Name)
+		}
+	}
+
+	errs := []error{}
+	for i, key := range n.Keys {
+		f := slices.Index(names, key.Value)
+		if f < 0 {
+			msg := fmt.Sprintf("%s: unknown field %q", key.Position(file), joinPath(path, key.Value))
+			if suggestion := closest(key.Value, names); suggestion != "" {
+				msg += fmt.Sprintf(", did you mean %q?", joinPath(path, suggestion))
+			}
+			errs = append(errs, errors.New(msg))
+			continue
+		}
+		errs = append(errs, validateField(file, n.Values[i], fields[f], joinPath(path, key.Value))...)
+	}
+	return errs
+}
+
+func validateField(file string, n *configNode, f configField, path string) []error {
+	t := f.Type
+	if t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice {
+		if n.Kind == KIND_NULL {
+			return nil
+		}
+	}
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		return validateObject(file, n, t, path)
+	case reflect.Map:
+		if n.Kind != KIND_OBJECT {
+			return []error{typeError(file, n, path, KIND_OBJECT)}
+		}
+		errs := []error{}
+		for i, key := range n.Keys {
+			if err := validBranchPattern(key.Value); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %s has an invalid pattern %q", key.Position(file), path, key.Value))
+				continue
+			}
+			errs = append(errs, validateObject(file, n.Values[i], t.Elem().Elem(), fmt.Sprintf("%s[%s]", path, key.Value), BRANCH_EXCLUDED...)...)
+		}
+		return errs
+	case reflect.Slice:
+		if n.Kind != KIND_ARRAY {
+			return []error{typeError(file, n, path, KIND_ARRAY)}
+		}
+		errs := []error{}
+		for i, e := range n.Values {
+			item := configField{Type: t.Elem(), Pattern: f.Pattern}
+			errs = append(errs, validateField(file, e, item, fmt.Sprintf("%s[%d]", path, i))...)
+		}
+		return errs
+	case reflect.Bool:
+		if n.Kind != KIND_BOOLEAN {
+			return []error{typeError(file, n, path, KIND_BOOLEAN)}
+		}
+	case reflect.String:
+		if n.Kind != KIND_STRING {
+			return []error{typeError(file, n, path, KIND_STRING)}
+		}
+		if len(f.Enum) > 0 && !slices.Contains(f.Enum, n.Value) {
+			return []error{fmt.Errorf("%s: %s must be one of %s, got %q", n.Position(file), path, SliceString(f.Enum), n.Value)}
+		}
+		if f.Pattern != "" && !regexp.MustCompile(f.Pattern).MatchString(n.Value) {
+			return []error{fmt.Errorf("%s: %s must match %s, got %q", n.Position(file), path, f.Pattern, n.Value)}
+		}
+	}
+	return nil
+}
+
+func typeError(file string, n *configNode, path, want string) error {
+	if path == "" {
+		return fmt.Errorf("%s: config must be an %s, got %s", n.Position(file), want, n.Kind)
+	}
+	article := "a"
+	if strings.ContainsRune("aeiou", rune(want[0])) {
+		article = "an"
+	}
+	return fmt.Errorf("%s: %s must be %s %s, got %s", n.Position(file), path, article, want, n.Kind)
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// closest returns the name most similar to s, or an empty string if none is close
+func closest(s string, names []string) string {
+	best, bestDistance := "", 3
+	for _, name := range names {
+		d := editDistance(strings.ToLower(s), strings.ToLower(name))
+		if d < bestDistance {
+			best, bestDistance = name, d
+		}
+	}
+	return best
+}
+
+// editDistance is the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,124 @@
+package internal_test
+
+import (
+	"os"
+	"testing"
+	"testing/fstest"
+
+	"github.com/mrvinkel/bump/internal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateSchema(t *testing.T) {
+	schema, err := internal.GenerateSchema()
+	require.NoError(t, err)
+
+	committed, err := os.ReadFile("../bump.schema.json")
+	require.NoError(t, err)
+
+	assert.Equal(t, string(committed), string(schema), "bump.schema.json is out of date, run go generate ./cmd/bump")
+}
+
+func TestReadConfigValidation(t *testing.T) {
+	type test struct {
+		name string
+		file string
+		data string
+		errs []string
+	}
+
+	tests := []test{
+		{
+			name: "unknown field",
+			file: internal.CONFIG_FILE,
+			data: `{
+  "prefix": "v",
+  "preHooks": ["make"]
+}`,
+			errs: []string{`.bump.json:3:3: unknown field "preHooks", did you mean "preHook"?`},
+		},
+		{
+			name: "unknown field without suggestion",
+			file: internal.CONFIG_FILE,
+			data: `{"release": true}`,
+			errs: []string{`.bump.json:1:2: unknown field "release"`},
+		},
+		{
+			name: "nested unknown field",
+			file: internal.CONFIG_FILE_YAML,
+			data: `author:
+  name: Release Bot
+  mail: bot@example.com
+`,
+			errs: []string{`.bump.yaml:3:3: unknown field "author.mail", did you mean "author.email"?`},
+		},
+		{
+			name: "wrong types",
+			file: internal.CONFIG_FILE_YAML,
+			data: `commit: "yes"
+preHook:
+  - make
+  - 1
+`,
+			errs: []string{
+				`.bump.yaml:1:9: commit must be a boolean, got string`,
+				`.bump.yaml:4:5: preHook[1] must be a string, got number`,
+			},
+		},
+		{
+			name: "enum",
+			file: internal.CONFIG_FILE,
+			data: `{"scheme": "semantic"}`,
+			errs: []string{`.bump.json:1:12: scheme must be one of semver, calver, pep440, go, numeric, got "semantic"`},
+		},
+		{
+			name: "pattern",
+			file: internal.CONFIG_FILE_YML,
+			data: `channels: [alpha, "1"]`,
+			errs: []string{`.bump.yml:1:19: channels[1] must match ^[0-9A-Za-z-]*[A-Za-z-][0-9A-Za-z-]*$, got "1"`},
+		},
+		{
+			name: "toml",
+			file: internal.CONFIG_FILE_TOML,
+			data: `prefix = "v"
+commit = "yes"
+
+[author]
+nam = "Release Bot"
+`,
+			errs: []string{
+				`.bump.toml:5:1: unknown field "author.nam", did you mean "author.name"?`,
+				`.bump.toml:2:1: commit must be a boolean, got string`,
+			},
+		},
+		{
+			name: "not an object",
+			file: internal.CONFIG_FILE,
+			data: `["prefix"]`,
+			errs: []string{`.bump.json:1:1: config must be an object, got array`},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			fs := fstest.MapFS{tc.file: &fstest.MapFile{Data: []byte(tc.data)}}
+
+			_, err := internal.ReadConfig(fs)
+			require.Error(t, err)
+			for _, msg := range tc.errs {
+				assert.ErrorContains(t, err, msg)
+			}
+		})
+	}
+}
+
+func TestReadConfigNull(t *testing.T) {
+	fs := fstest.MapFS{internal.CONFIG_FILE_YAML: &fstest.MapFile{Data: []byte("prefix:\npreHook:\n")}}
+
+	config, err := internal.ReadConfig(fs)
+	require.NoError(t, err)
+
+	assert.Nil(t, config.Prefix)
+	assert.Empty(t, config.PreHook)
+}
@@ -0,0 +1,126 @@
+package internal_test
+
+import (
+	"testing"
+
+	"testing/fstest"
+
+	"github.com/mrvinkel/bump/internal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadConfigNotExist(t *testing.T) {
+	fs := fstest.MapFS{}
+
+	config, err := internal.ReadConfig(fs)
+
+	assert.Nil(t, err)
+	assert.Nil(t, config)
+}
+
+func TestReadConfig(t *testing.T) {
+	fs := fstest.MapFS{
+		internal.CONFIG_FILE: &fstest.MapFile{
+			Data: []byte(`{"commit": false}`),
+		},
+	}
+
+	config, err := internal.ReadConfig(fs)
+
+	assert.Nil(t, err)
+	assert.NotNil(t, config)
+
+	assert.False(t, *config.Commit)
+
+	// defaults
+	assert.Equal(t, "release {{.Version}}", *config.Message)
+	assert.Nil(t, config.Prefix)
+	assert.Nil(t, config.Fetch)
+	assert.Nil(t, config.Verify)
+	assert.Equal(t, "/bin/bash -c", *config.Shell)
+	assert.Empty(t, config.PreHook)
+}
+
+func TestReadConfigFormats(t *testing.T) {
+	type test struct {
+		file string
+		data string
+	}
+
+	tests := []test{
+		{
+			file: internal.CONFIG_FILE,
+			data: `{
+  // comments and trailing commas are allowed
+  "prefix": "v",
+  "author": { "name": "Release Bot", "email": "bot@example.com" },
+  /* block comment */
+  "preHook": ["make", "make test",],
+}`,
+		},
+		{
+			file: internal.CONFIG_FILE_YAML,
+			data: `# comment
+prefix: v
+author:
+  name: Release Bot
+  email: bot@example.com
+preHook:
+  - make
+  - make test
+`,
+		},
+		{
+			file: internal.CONFIG_FILE_YML,
+			data: `{prefix: v, author: {name: Release Bot, email: bot@example.com}, preHook: [make, make test]}`,
+		},
+		{
+			file: internal.CONFIG_FILE_TOML,
+			data: `# comment
+prefix = "v"
+preHook = ["make", "make test"]
+
+[author]
+name = "Release Bot"
+email = "bot@example.com"
+`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.file, func(t *testing.T) {
+			fs := fstest.MapFS{tc.file: &fstest.MapFile{Data: []byte(tc.data)}}
+
+			config, err := internal.ReadConfig(fs)
+			require.NoError(t, err)
+
+			assert.Equal(t, "v", *config.Prefix)
+			assert.Equal(t, &internal.Identity{Name: "Release Bot", Email: "bot@example.com"}, config.Author)
+			assert.Equal(t, []string{"make", "make test"}, config.PreHook)
+			assert.Equal(t, "release {{.Version}}", *config.Message)
+		})
+	}
+}
+
+func TestReadConfigInvalid(t *testing.T) {
+	fs := fstest.MapFS{internal.CONFIG_FILE_YAML: &fstest.MapFile{Data: []byte("prefix: [v")}}
+
+	_, err := internal.ReadConfig(fs)
+	assert.ErrorContains(t, err, ".bump.yaml")
+
+	fs = fstest.MapFS{internal.CONFIG_FILE_TOML: &fstest.MapFile{Data: []byte(`commit = "yes"`)}}
+
+	_, err = internal.ReadConfig(fs)
+	assert.ErrorContains(t, err, ".bump.toml")
+}
+
+func TestReadConfigMoreThanOne(t *testing.T) {
+	fs := fstest.MapFS{
+		internal.CONFIG_FILE:      &fstest.MapFile{Data: []byte(`{}`)},
+		internal.CONFIG_FILE_YAML: &fstest.MapFile{Data: []byte(`{}`)},
+	}
+
+	_, err := internal.ReadConfig(fs)
+	assert.EqualError(t, err, "more than one config file: .bump.json, .bump.yaml, keep only one")
+}
@@ -0,0 +1,124 @@
+package internal
+
+import (
+	"fmt"
+
+	"github.com/mrvinkel/bump/semver"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// build version styles of describe
+	DESCRIBE_DEV      = "dev"
+	DESCRIBE_SNAPSHOT = "snapshot"
+
+	DIRTY_MARKER = "dirty"
+	SHORT_SHA    = 7
+)
+
+// Describe prints a build version for HEAD, or the commit of --ref, without tagging it,
+// e.g. v1.4.3-dev.7+g1a2b3c4. Changes in the worktree only mark HEAD as dirty.
+func Describe(cmd *cobra.Command, args []string) error {
+	rc, err := commandContext(cmd)
+	if err != nil {
+		return err
+	}
+	repo, config := rc.repo, rc.config
+
+	scheme, err := SchemeFor(config)
+	if err != nil {
+		return err
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return err
+	}
+	target, err := releaseTarget(rc, head)
+	if err != nil {
+		return err
+	}
+	latest, err := getLatestVersion(rc, scheme, target)
+	if err != nil {
+		return err
+	}
+	commits, err := repo.CommitsSince(target, tagName(latest))
+	if err != nil {
+		return err
+	}
+	dirty := false
+	if target == head {
+		if dirty, _, err = repo.HasChanges(); err != nil {
+			return err
+		}
+	}
+
+	style, err := cmd.Flags().GetString("style")
+	if err != nil {
+		return err
+	}
+	version, err := DescribeVersion(latest, commits, target.String()[:SHORT_SHA], dirty, style)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(cmd.OutOrStdout(), tagName(version))
+	return err
+}
+
+// DescribeVersion returns the build version of a commit the given number of commits
+// after the latest version. The latest version is returned as is for a clean checkout
+// of the tagged commit.
+//
+// The dev style is the next patch version with a dev pre-release counting the commits
+// and the short SHA as build metadata, e.g. v1.4.3-dev.7+g1a2b3c4, or v1.4.3.dev7+g1a2b3c4
+// for PEP 440. The snapshot style is the next patch version in Maven style, e.g.
+// v1.4.3-SNAPSHOT.
+func DescribeVersion(latest Versioned, commits int, sha string, dirty bool, style string) (Versioned, error) {
+	if commits == 0 && !dirty {
+		return latest, nil
+	}
+
+	build := "g" + sha
+	if dirty {
+		build += "." + DIRTY_MARKER
+	}
+
+	switch v := latest.(type) {
+	case *semver.Version:
+		next := semver.New(v.Prefix, v.Major, v.Minor, v.Patch, v.PreRelease, nil)
+		if len(v.PreRelease) == 0 {
+			next = semver.BumpPatch(next)
+		}
+		switch style {
+		case DESCRIBE_DEV:
+			// a pre-release continues, 1.4.3-rc.1 -> 1.4.3-rc.1.dev.7
+			next.PreRelease = append(append([]string{}, next.PreRelease...), "dev", fmt.Sprint(commits))
+			next.Build = &build
+		case DESCRIBE_SNAPSHOT:
+			next.PreRelease = []string{"SNAPSHOT"}
+		default:
+			return nil, fmt.Errorf("unknown describe style %q, must be %s or %s", style, DESCRIBE_DEV, DESCRIBE_SNAPSHOT)
+		}
+		return next, nil
+	case *PEP440Version:
+		if style != DESCRIBE_DEV {
+			return nil, fmt.Errorf("describe style %s is not supported by the %s scheme", style, SCHEME_PEP440)
+		}
+		next := v.copy()
+		switch {
+		case v.Post != nil:
+			next.Post = new(*v.Post + 1)
+		case v.PreLabel != "":
+			next.PreNum++
+		default:
+			var err error
+			if next, err = BumpPEP440(v, LEVEL_PATCH); err != nil {
+				return nil, err
+			}
+		}
+		next.Dev = &commits
+		next.Local = &build
+		return next, nil
+	}
+	return nil, fmt.Errorf("describe is not supported for %s", latest)
+}
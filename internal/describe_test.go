@@ -0,0 +1,120 @@
+package internal_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/mrvinkel/bump/internal"
+	"github.com/mrvinkel/bump/internal/gittest"
+	"github.com/mrvinkel/bump/semver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescribeVersion(t *testing.T) {
+	type test struct {
+		name     string
+		latest   string
+		pep440   bool
+		commits  int
+		dirty    bool
+		style    string
+		expected string
+		err      bool
+	}
+
+	tests := []test{
+		{name: "tagged", latest: "v1.4.2", style: internal.DESCRIBE_DEV, expected: "v1.4.2"},
+		{name: "dev", latest: "v1.4.2", commits: 7, style: internal.DESCRIBE_DEV, expected: "v1.4.3-dev.7+g1a2b3c4"},
+		{name: "dev dirty", latest: "v1.4.2", commits: 7, dirty: true, style: internal.DESCRIBE_DEV, expected: "v1.4.3-dev.7+g1a2b3c4.dirty"},
+		{name: "tagged dirty", latest: "v1.4.2", dirty: true, style: internal.DESCRIBE_DEV, expected: "v1.4.3-dev.0+g1a2b3c4.dirty"},
+		{name: "dev pre-release", latest: "v1.4.3-rc.1", commits: 2, style: internal.DESCRIBE_DEV, expected: "v1.4.3-rc.1.dev.2+g1a2b3c4"},
+		{name: "dev drops build", latest: "1.4.2+build.5", commits: 1, style: internal.DESCRIBE_DEV, expected: "1.4.3-dev.1+g1a2b3c4"},
+		{name: "snapshot", latest: "v1.4.2", commits: 7, style: internal.DESCRIBE_SNAPSHOT, expected: "v1.4.3-SNAPSHOT"},
+		{name: "snapshot pre-release", latest: "v1.4.3-rc.1", commits: 7, style: internal.DESCRIBE_SNAPSHOT, expected: "v1.4.3-SNAPSHOT"},
+		{name: "unknown style", latest: "v1.4.2", commits: 7, style: "nightly", err: true},
+		{name: "pep440", latest: "1.4.2", pep440: true, commits: 7, style: internal.DESCRIBE_DEV, expected: "1.4.3.dev7+g1a2b3c4"},
+		{name: "pep440 pre-release", latest: "1.4.3rc1", pep440: true, commits: 7, dirty: true, style: internal.DESCRIBE_DEV, expected: "1.4.3rc2.dev7+g1a2b3c4.dirty"},
+		{name: "pep440 post-release", latest: "1.4.3.post1", pep440: true, commits: 7, style: internal.DESCRIBE_DEV, expected: "1.4.3.post2.dev7+g1a2b3c4"},
+		{name: "pep440 snapshot", latest: "1.4.3", pep440: true, commits: 7, style: internal.DESCRIBE_SNAPSHOT, err: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var latest internal.Versioned
+			var err error
+			if tc.pep440 {
+				latest, err = internal.ParsePEP440Version(tc.latest)
+			} else {
+				latest, err = semver.Parse(tc.latest)
+			}
+			require.NoError(t, err)
+
+			actual, err := internal.DescribeVersion(latest, tc.commits, "1a2b3c4", tc.dirty, tc.style)
+			if tc.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual.String())
+			if tc.style == internal.DESCRIBE_DEV && tc.commits > 0 {
+				assert.Equal(t, 1, actual.Compare(latest), "build versions sort after the latest version")
+			}
+		})
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	dir, _ := gittest.NewRepo(t, map[string]string{})
+	t.Chdir(dir)
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	first := gittest.Commit(t, dir, map[string]string{"a.txt": "a"}, "a")
+	second := gittest.Commit(t, dir, map[string]string{"b.txt": "b"}, "b")
+	gittest.Push(t, dir)
+	gittest.WriteFiles(t, dir, map[string]string{"b.txt": "changed"})
+
+	tests := map[string]struct {
+		args     []string
+		expected string
+	}{
+		"head":    {args: []string{}, expected: "v1.0.1-dev.2+g" + second.String()[:internal.SHORT_SHA] + ".dirty"},
+		"ref":     {args: []string{"--ref", first.String()}, expected: "v1.0.1-dev.1+g" + first.String()[:internal.SHORT_SHA]},
+		"tag ref": {args: []string{"--ref", "v1.0.0"}, expected: "v1.0.0"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			cmd := bumpCommand(internal.Describe)
+			cmd.Flags().String("style", internal.DESCRIBE_DEV, "")
+			out := &bytes.Buffer{}
+			cmd.SetOut(out)
+			cmd.SetArgs(tc.args)
+			require.NoError(t, cmd.Execute())
+			assert.Equal(t, tc.expected+"\n", out.String())
+		})
+	}
+}
+
+func TestDescribeNonNormalizedTag(t *testing.T) {
+	dir, _ := gittest.NewRepo(t, map[string]string{".bump.json": `{"scheme": "pep440"}`})
+	tagHead("v1.1.0-rc.1")(t, dir)
+	t.Chdir(dir)
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	describe := func() string {
+		cmd := bumpCommand(internal.Describe)
+		cmd.Flags().String("style", internal.DESCRIBE_DEV, "")
+		out := &bytes.Buffer{}
+		cmd.SetOut(out)
+		cmd.SetArgs([]string{})
+		require.NoError(t, cmd.Execute())
+		return out.String()
+	}
+
+	// the tag is printed as it is
+	assert.Equal(t, "v1.1.0-rc.1\n", describe())
+
+	hash := gittest.Commit(t, dir, map[string]string{"a.txt": "a"}, "a")
+	assert.Equal(t, "v1.1.0rc2.dev1+g"+hash.String()[:internal.SHORT_SHA]+"\n", describe())
+}
@@ -0,0 +1,188 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	// RELEASE_TRAILER marks release commits created with --via-branch
+	RELEASE_TRAILER = "Bump-Version"
+	// OUTPUT_TRAILER carries pre-hook outputs from the release commit to finalize
+	OUTPUT_TRAILER = "Bump-Output"
+)
+
+// Finalize tags a release commit created with --via-branch once it has been merged.
+// The version to finalize can be given as the first argument, otherwise the newest
+// untagged release commit reachable from HEAD is used. The tag goes on the first
+// commit of the main line containing it: the merge commit of a merge, or the commit of
+// a squash merge, which is found by the trailers kept in its message.
+func Finalize(cmd *cobra.Command, args []string) error {
+	rc, err := commandContext(cmd)
+	if err != nil {
+		return err
+	}
+	repo, config := rc.repo, rc.config
+	ctx := commandCtx(cmd)
+
+	if err = checkPush(rc); err != nil {
+		return err
+	}
+
+	if rc.verify {
+		err = checkRepositoryStatus(ctx, rc)
+		if err != nil {
+			return err
+		}
+	}
+
+	scheme, err := SchemeFor(config)
+	if err != nil {
+		return err
+	}
+
+	want := ""
+	if len(args) > 0 {
+		v, err := scheme.Parse(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid version %s: %w", args[0], err)
+		}
+		want = v.String()
+	}
+
+	release, err := findReleaseCommit(repo, want)
+	if err != nil {
+		return err
+	}
+	rc.log.Debug("release commit", "hash", release.Hash)
+
+	// with a merge the release commit is on the release branch, tag the merge commit
+	target, err := repo.MainlineCommit(release.Hash)
+	if err != nil {
+		return err
+	}
+
+	newVersion, err := scheme.Parse(release.Value)
+	if err != nil {
+		return fmt.Errorf("invalid %s trailer in %s: %w", RELEASE_TRAILER, release.Hash, err)
+	}
+
+	previousVersion, err := getLatestVersion(rc, scheme, target)
+	if err != nil {
+		return err
+	}
+
+	gitIdentities, err := repo.Identities()
+	if err != nil {
+		return err
+	}
+	_, committer := ResolveIdentities(config, gitIdentities, rc.getenv)
+
+	outputs, err := ParseOutputTrailers(release.Message)
+	if err != nil {
+		return err
+	}
+
+	data, err := messageData(repo, target, newVersion, previousVersion, outputs)
+	if err != nil {
+		return err
+	}
+
+	tagMessage := ""
+	if config != nil && config.TagMessage != nil {
+		tagMessage, err = RenderMessage(*config.TagMessage, data)
+		if err != nil {
+			return err
+		}
+		if err = CheckIdentity("committer", committer); err != nil {
+			return err
+		}
+	}
+
+	rc.log.Info("tag", "previous", previousVersion.String(), "version", newVersion.String(), "commit", target)
+
+	if rc.dryRun {
+		rc.log.Info("dry run, will not create tag")
+		return nil
+	}
+
+	err = repo.TagAndPush(ctx, newVersion.String(), target, tagMessage, committer)
+	if err != nil {
+		return err
+	}
+
+	err = runPostHook(ctx, rc, releaseVars(newVersion, previousVersion, outputs))
+	if err != nil {
+		rc.log.Debug("post-hook failed", "error", err)
+		return errors.New("post-hook failed")
+	}
+	return nil
+}
+
+// findReleaseCommit finds the release commit for a version, or the newest release
+// commit that has not been tagged yet if no version is given.
+func findReleaseCommit(repo *Repo, version string) (*TrailerCommit, error) {
+	commits, err := repo.FindTrailer(RELEASE_TRAILER)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, c := range commits {
+		if version != "" && c.Value != version {
+			continue
+		}
+		tagged, err := repo.HasTag(c.Value)
+		if err != nil {
+			return nil, err
+		}
+		if tagged {
+			if version != "" {
+				return nil, fmt.Errorf("%s is already tagged", version)
+			}
+			continue
+		}
+		return &c, nil
+	}
+
+	if version != "" {
+		return nil, fmt.Errorf("no release commit for %s found, merge the release branch first", version)
+	}
+	return nil, errors.New("no untagged release commit found, merge the release branch first")
+}
+
+// OutputTrailers encodes pre-hook outputs as trailers, sorted by key
+func OutputTrailers(outputs map[string]string) []string {
+	keys := make([]string, 0, len(outputs))
+	for key := range outputs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	trailers := make([]string, 0, len(keys))
+	for _, key := range keys {
+		trailers = append(trailers, fmt.Sprintf("%s: %s=%s", OUTPUT_TRAILER, key, strconv.Quote(outputs[key])))
+	}
+	return trailers
+}
+
+// ParseOutputTrailers decodes pre-hook outputs encoded by OutputTrailers
+func ParseOutputTrailers(message string) (map[string]string, error) {
+	outputs := map[string]string{}
+	for _, trailer := range Trailers(message, OUTPUT_TRAILER) {
+		key, quoted, ok := strings.Cut(trailer, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid %s trailer: %s", OUTPUT_TRAILER, trailer)
+		}
+		value, err := strconv.Unquote(quoted)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s trailer: %s", OUTPUT_TRAILER, trailer)
+		}
+		outputs[key] = value
+	}
+	return outputs, nil
+}
@@ -0,0 +1,74 @@
+package internal_test
+
+import (
+	"testing"
+
+	"github.com/mrvinkel/bump/internal"
+	"github.com/mrvinkel/bump/internal/gittest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutputTrailers(t *testing.T) {
+	outputs := map[string]string{
+		"SUM":   "abc123",
+		"NOTES": "line 1\nline \"2\"",
+	}
+
+	trailers := internal.OutputTrailers(outputs)
+	assert.Equal(t, []string{
+		`Bump-Output: NOTES="line 1\nline \"2\""`,
+		`Bump-Output: SUM="abc123"`,
+	}, trailers)
+
+	message := internal.AddTrailers("release v1.0.0", append([]string{"Bump-Version: v1.0.0"}, trailers...)...)
+	actual, err := internal.ParseOutputTrailers(message)
+	require.NoError(t, err)
+	assert.Equal(t, outputs, actual)
+	assert.Equal(t, []string{"v1.0.0"}, internal.Trailers(message, internal.RELEASE_TRAILER))
+}
+
+func TestParseOutputTrailersInvalid(t *testing.T) {
+	_, err := internal.ParseOutputTrailers("release\n\nBump-Output: SUM=abc")
+	assert.Error(t, err)
+
+	_, err = internal.ParseOutputTrailers("release\n\nBump-Output: SUM")
+	assert.Error(t, err)
+}
+
+func TestFinalize(t *testing.T) {
+	type test struct {
+		squash bool
+	}
+
+	tests := map[string]test{
+		"merge":  {},
+		"squash": {squash: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir, origin := gittest.NewRepo(t, map[string]string{".bump.json": versionHook})
+			t.Chdir(dir)
+			t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+			result, err := internal.Release(t.Context(), internal.Options{Dir: dir, Level: internal.LEVEL_MINOR, ViaBranch: true, Getenv: gittest.Getenv(t)})
+			require.NoError(t, err)
+
+			// main moves on while the release branch is reviewed
+			gittest.Commit(t, dir, map[string]string{"a.txt": "a"}, "a")
+			merged := gittest.Merge(t, dir, result.ReleaseBranch, tc.squash)
+			gittest.Push(t, dir)
+
+			cmd := bumpCommand(internal.Finalize)
+			cmd.SetArgs([]string{})
+			require.NoError(t, cmd.Execute())
+
+			tag, err := origin.Tag("v1.1.0")
+			require.NoError(t, err)
+			assert.Equal(t, merged, tag.Hash())
+			assert.Equal(t, "v1.1.0\n", gittest.FileAt(t, origin, tag.Hash(), "VERSION"))
+			assert.Equal(t, "a", gittest.FileAt(t, origin, tag.Hash(), "a.txt"))
+		})
+	}
+}
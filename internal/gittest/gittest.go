@@ -0,0 +1,188 @@
+// Package gittest creates git repositories with a bare origin for tests of the release
+// flow.
+package gittest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/require"
+)
+
+// Signature is the author of the commits of the tests
+func Signature() *object.Signature {
+	return &object.Signature{Name: "Test", Email: "test@example.com", When: time.Now()}
+}
+
+// NewRepo creates a repository with a commit of the files and a readme.md, tagged
+// v1.0.0 and pushed to a bare origin. The work tree and the origin are returned.
+func NewRepo(t *testing.T, files map[string]string) (string, *git.Repository) {
+	t.Helper()
+	root := t.TempDir()
+	originDir := filepath.Join(root, "origin.git")
+	origin, err := git.PlainInit(originDir, true)
+	require.NoError(t, err)
+
+	dir := filepath.Join(root, "work")
+	repo, err := git.PlainInit(dir, false)
+	require.NoError(t, err)
+	_, err = repo.CreateRemote(&config.RemoteConfig{Name: "origin", URLs: []string{originDir}})
+	require.NoError(t, err)
+
+	all := map[string]string{"readme.md": "# test\n"}
+	for name, data := range files {
+		all[name] = data
+	}
+	hash := Commit(t, dir, all, "initial")
+	_, err = repo.CreateTag("v1.0.0", hash, nil)
+	require.NoError(t, err)
+
+	Push(t, dir)
+	return dir, origin
+}
+
+// Commit writes the files to the work tree and commits them
+func Commit(t *testing.T, dir string, files map[string]string, message string) plumbing.Hash {
+	t.Helper()
+	WriteFiles(t, dir, files)
+
+	repo, err := git.PlainOpen(dir)
+	require.NoError(t, err)
+	w, err := repo.Worktree()
+	require.NoError(t, err)
+	_, err = w.Add(".")
+	require.NoError(t, err)
+	hash, err := w.Commit(message, &git.CommitOptions{Author: Signature()})
+	require.NoError(t, err)
+	return hash
+}
+
+// WriteFiles writes the files to the work tree without committing them
+func WriteFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(data), 0o644))
+	}
+}
+
+// Push pushes the branches and tags of the work tree to origin and fetches them back,
+// so the remote branches are up to date
+func Push(t *testing.T, dir string) {
+	t.Helper()
+	repo, err := git.PlainOpen(dir)
+	require.NoError(t, err)
+	err = repo.Push(&git.PushOptions{RefSpecs: []config.RefSpec{"refs/heads/*:refs/heads/*", "refs/tags/*:refs/tags/*"}})
+	if err != git.NoErrAlreadyUpToDate {
+		require.NoError(t, err)
+	}
+	if err = repo.Fetch(&git.FetchOptions{}); err != git.NoErrAlreadyUpToDate {
+		require.NoError(t, err)
+	}
+}
+
+// AddRemote creates another bare repository next to origin and adds it as a remote of
+// the work tree with the name. The branches and tags are pushed to it and fetched back.
+func AddRemote(t *testing.T, dir, name string) *git.Repository {
+	t.Helper()
+	remoteDir := filepath.Join(filepath.Dir(dir), name+".git")
+	remote, err := git.PlainInit(remoteDir, true)
+	require.NoError(t, err)
+
+	repo, err := git.PlainOpen(dir)
+	require.NoError(t, err)
+	_, err = repo.CreateRemote(&config.RemoteConfig{Name: name, URLs: []string{remoteDir}})
+	require.NoError(t, err)
+	err = repo.Push(&git.PushOptions{RemoteName: name, RefSpecs: []config.RefSpec{"refs/heads/*:refs/heads/*", "refs/tags/*:refs/tags/*"}})
+	require.NoError(t, err)
+	err = repo.Fetch(&git.FetchOptions{RemoteName: name})
+	if err != git.NoErrAlreadyUpToDate {
+		require.NoError(t, err)
+	}
+	return remote
+}
+
+// Getenv returns an environment without a user config and with the identity of the
+// release commits
+func Getenv(t *testing.T) func(string) string {
+	env := map[string]string{
+		"XDG_CONFIG_HOME":   t.TempDir(),
+		"BUMP_AUTHOR_NAME":  "Release Bot",
+		"BUMP_AUTHOR_EMAIL": "release@example.com",
+	}
+	return func(key string) string {
+		return env[key]
+	}
+}
+
+// FileAt returns the contents of a file in a commit of the repository
+func FileAt(t *testing.T, repo *git.Repository, hash plumbing.Hash, name string) string {
+	t.Helper()
+	commit, err := repo.CommitObject(hash)
+	require.NoError(t, err)
+	file, err := commit.File(name)
+	require.NoError(t, err)
+	contents, err := file.Contents()
+	require.NoError(t, err)
+	return contents
+}
+
+// Merge merges the branch into the checked out branch with a merge commit, or with a
+// single commit keeping the message of the branch like a squash merge. The files changed
+// on the branch since the merge base are taken from it, so there must be no conflicts.
+func Merge(t *testing.T, dir, branch string, squash bool) plumbing.Hash {
+	t.Helper()
+	repo, err := git.PlainOpen(dir)
+	require.NoError(t, err)
+	head, err := repo.Head()
+	require.NoError(t, err)
+	headCommit, err := repo.CommitObject(head.Hash())
+	require.NoError(t, err)
+	ref, err := repo.Reference(plumbing.NewBranchReferenceName(branch), true)
+	require.NoError(t, err)
+	branchCommit, err := repo.CommitObject(ref.Hash())
+	require.NoError(t, err)
+
+	bases, err := headCommit.MergeBase(branchCommit)
+	require.NoError(t, err)
+	require.Len(t, bases, 1)
+	baseTree, err := bases[0].Tree()
+	require.NoError(t, err)
+	branchTree, err := branchCommit.Tree()
+	require.NoError(t, err)
+
+	files := map[string]string{}
+	require.NoError(t, branchTree.Files().ForEach(func(f *object.File) error {
+		contents, err := f.Contents()
+		if err != nil {
+			return err
+		}
+		if base, err := baseTree.File(f.Name); err == nil && base.Hash == f.Hash {
+			return nil
+		}
+		files[f.Name] = contents
+		return nil
+	}))
+	WriteFiles(t, dir, files)
+
+	w, err := repo.Worktree()
+	require.NoError(t, err)
+	_, err = w.Add(".")
+	require.NoError(t, err)
+	options := &git.CommitOptions{Author: Signature(), Parents: []plumbing.Hash{head.Hash(), ref.Hash()}}
+	message := "Merge branch " + branch
+	if squash {
+		options.Parents = nil
+		message = branch + " (#1)\n\n* " + branchCommit.Message
+	}
+	hash, err := w.Commit(message, options)
+	require.NoError(t, err)
+	return hash
+}
@@ -0,0 +1,273 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mrvinkel/bump/semver"
+)
+
+const (
+	SCHEME_GO = "go"
+
+	GO_MOD_FILE = "go.mod"
+
+	// what to do when a major bump is missing the /vN module path suffix
+	GO_MODULE_IGNORE  = "ignore"
+	GO_MODULE_WARN    = "warn"
+	GO_MODULE_ERROR   = "error"
+	GO_MODULE_REWRITE = "rewrite"
+)
+
+var (
+	goModuleLine  = regexp.MustCompile(`(?m)^(module\s+)("?)([^\s"]+)("?)`)
+	goMajorSuffix = regexp.MustCompile(`/v[0-9]+$`)
+	errNoGoPrefix = errors.New("go module versions must have the prefix v or <dir>/v")
+	errNoGoBuild  = errors.New("go module versions can not have build metadata")
+	goModuleModes = []string{GO_MODULE_IGNORE, GO_MODULE_WARN, GO_MODULE_ERROR, GO_MODULE_REWRITE}
+)
+
+// GoSemVer is strict SemVer as used by Go modules: the tag prefix is v, or <dir>/v for
+// a module in a sub directory, and build metadata is not allowed.
+type GoSemVer struct{}
+
+func (GoSemVer) Name() string {
+	return SCHEME_GO
+}
+
+func (s GoSemVer) Parse(tag string) (Versioned, error) {
+	v, err := semver.ParseStrict(tag)
+	if err != nil {
+		return nil, err
+	}
+	if err = s.Validate(v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func (GoSemVer) Initial() Versioned {
+	return semver.New(new("v"), "0", "0", "0", []string{}, nil)
+}
+
+func (GoSemVer) Validate(v Versioned) error {
+	version, ok := v.(*semver.Version)
+	if !ok {
+		return fmt.Errorf("%s is not a semantic version", v)
+	}
+	if version.Prefix == nil || (*version.Prefix != "v" && !strings.HasSuffix(*version.Prefix, "/v")) {
+		return errNoGoPrefix
+	}
+	if version.Build != nil {
+		return errNoGoBuild
+	}
+	return semver.Validate(version)
+}
+
+// GoModule is a go.mod file and the module path declared in it
+type GoModule struct {
+	File string
+	Path string
+}
+
+// FindGoModule finds the go.mod for a version tag. Tags with a <dir>/v prefix belong to
+// the module in dir, other tags to the module in the repository root. Nil is returned
+// if there is no go.mod.
+func FindGoModule(repoDir string, prefix *string) (*GoModule, error) {
+	dir := repoDir
+	if prefix != nil && strings.HasSuffix(*prefix, "/v") {
+		dir = filepath.Join(repoDir, filepath.FromSlash(strings.TrimSuffix(*prefix, "/v")))
+	}
+
+	file := filepath.Join(dir, GO_MOD_FILE)
+	content, err := os.ReadFile(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	match := goModuleLine.FindSubmatch(content)
+	if match == nil {
+		return nil, fmt.Errorf("no module directive in %s", file)
+	}
+	return &GoModule{File: file, Path: string(match[3])}, nil
+}
+
+// GoModulePath returns the module path for a major version, e.g. example.com/mod/v2
+func GoModulePath(path string, major int) string {
+	path = goMajorSuffix.ReplaceAllString(path, "")
+	if major >= 2 {
+		path = fmt.Sprintf("%s/v%d", path, major)
+	}
+	return path
+}
+
+// CheckGoModule checks that the module path has the /vN suffix of the new major version.
+// Depending on mode a missing suffix is ignored, logged as a warning, an error, or the
+// module and import paths are rewritten. A rewrite returns a function restoring the
+// files, for when the release fails before the rewrite is committed.
+func CheckGoModule(log *slog.Logger, repoDir, mode string, previousVersion, newVersion *semver.Version, dryRun bool) (func() error, error) {
+	if mode == GO_MODULE_IGNORE {
+		return nil, nil
+	}
+	mod, want, err := GoModuleMismatch(repoDir, previousVersion, newVersion)
+	if err != nil || mod == nil {
+		return nil, err
+	}
+
+	switch mode {
+	case GO_MODULE_WARN:
+		log.Warn("module path does not match the major version", "path", mod.Path, "file", mod.File, "want", want, "version", newVersion.String())
+		return nil, nil
+	case GO_MODULE_REWRITE:
+		log.Info("rewriting module path", "path", mod.Path, "new", want)
+		if dryRun {
+			log.Info("dry run, will not rewrite module path")
+			return nil, nil
+		}
+		return RewriteGoModule(log, mod, want)
+	case GO_MODULE_ERROR:
+		return nil, fmt.Errorf("module path %s in %s must be %s for %s, change it or set \"goModule\": \"rewrite\"", mod.Path, mod.File, want, newVersion)
+	}
+	return nil, fmt.Errorf("invalid goModule %q, must be one of %s", mode, SliceString(goModuleModes))
+}
+
+// GoModuleMismatch returns the go.mod of a major bump whose module path does not have
+// the /vN suffix of the new major version, and the path it should have. Nil is
+// returned if the major version does not change or the path matches.
+func GoModuleMismatch(repoDir string, previousVersion, newVersion *semver.Version) (*GoModule, string, error) {
+	if previousVersion.Major == newVersion.Major {
+		return nil, "", nil
+	}
+
+	mod, err := FindGoModule(repoDir, newVersion.Prefix)
+	if err != nil || mod == nil {
+		return nil, "", err
+	}
+
+	major, err := newVersion.Major.Int()
+	if err != nil {
+		return nil, "", fmt.Errorf("major version %w", err)
+	}
+	want := GoModulePath(mod.Path, major)
+	if mod.Path == want {
+		return nil, "", nil
+	}
+	return mod, want, nil
+}
+
+// RewriteGoModule changes the module path in go.mod and the imports of the module's
+// packages in all .go files of the module. Nested modules and vendor are skipped. The
+// returned function restores the files; they are restored right away if it fails.
+func RewriteGoModule(log *slog.Logger, mod *GoModule, newPath string) (func() error, error) {
+	originals := map[string][]byte{}
+	restore := func() error {
+		errs := []error{}
+		for file, content := range originals {
+			errs = append(errs, os.WriteFile(file, content, 0o644))
+		}
+		return errors.Join(errs...)
+	}
+
+	content, err := os.ReadFile(mod.File)
+	if err != nil {
+		return nil, err
+	}
+	originals[mod.File] = content
+	content = goModuleLine.ReplaceAll(content, []byte("${1}${2}"+newPath+"${4}"))
+	if err = os.WriteFile(mod.File, content, 0o644); err != nil {
+		return nil, errors.Join(err, restore())
+	}
+
+	root := filepath.Dir(mod.File)
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path == root {
+				return nil
+			}
+			if d.Name() == "vendor" || strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			if _, err := os.Stat(filepath.Join(path, GO_MOD_FILE)); err == nil {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".go") {
+			return nil
+		}
+		return rewriteImports(log, path, mod.Path, newPath, originals)
+	})
+	if err != nil {
+		return nil, errors.Join(err, restore())
+	}
+	return restore, nil
+}
+
+// rewriteImports rewrites the imports of the old module path in a file. The content
+// before the rewrite is added to originals.
+func rewriteImports(log *slog.Logger, file, oldPath, newPath string, originals map[string][]byte) error {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, file, content, parser.ImportsOnly)
+	if err != nil {
+		return err
+	}
+
+	type replacement struct {
+		start, end int
+		path       string
+	}
+	replacements := []replacement{}
+	for _, imp := range f.Imports {
+		path, err := strconv.Unquote(imp.Path.Value)
+		if err != nil {
+			return err
+		}
+		if path != oldPath && !strings.HasPrefix(path, oldPath+"/") {
+			continue
+		}
+		start := fset.Position(imp.Path.Pos()).Offset
+		end := fset.Position(imp.Path.End()).Offset
+		replacements = append(replacements, replacement{start, end, strconv.Quote(newPath + strings.TrimPrefix(path, oldPath))})
+	}
+	if len(replacements) == 0 {
+		return nil
+	}
+
+	originals[file] = slices.Clone(content)
+	// replace from the end so offsets stay valid
+	sort.Slice(replacements, func(i, j int) bool {
+		return replacements[i].start > replacements[j].start
+	})
+	for _, r := range replacements {
+		content = append(content[:r.start], append([]byte(r.path), content[r.end:]...)...)
+	}
+
+	log.Debug("rewriting imports", "file", file)
+	info, err := os.Stat(file)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file, content, info.Mode())
+}
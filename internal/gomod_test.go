@@ -0,0 +1,170 @@
+package internal_test
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mrvinkel/bump/internal"
+	"github.com/mrvinkel/bump/semver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGoModulePath(t *testing.T) {
+	assert.Equal(t, "example.com/mod", internal.GoModulePath("example.com/mod", 1))
+	assert.Equal(t, "example.com/mod/v2", internal.GoModulePath("example.com/mod", 2))
+	assert.Equal(t, "example.com/mod/v3", internal.GoModulePath("example.com/mod/v2", 3))
+	assert.Equal(t, "example.com/mod", internal.GoModulePath("example.com/mod/v2", 1))
+}
+
+func TestGoSemVerParse(t *testing.T) {
+	scheme := internal.GoSemVer{}
+
+	for _, tag := range []string{"v1.2.3", "v1.2.3-rc.1", "sub/dir/v0.1.0"} {
+		_, err := scheme.Parse(tag)
+		assert.NoError(t, err, tag)
+	}
+	for _, tag := range []string{"1.2.3", "x1.2.3", "v1.2.3+build", "sub/dir-v1.0.0"} {
+		_, err := scheme.Parse(tag)
+		assert.Error(t, err, tag)
+	}
+
+	assert.Equal(t, "v0.0.0", scheme.Initial().String())
+}
+
+func writeModule(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+	return dir
+}
+
+func TestCheckGoModule(t *testing.T) {
+	discard := slog.New(slog.DiscardHandler)
+	checkGoModule := func(dir, mode string, previous, next *semver.Version, dryRun bool) error {
+		_, err := internal.CheckGoModule(discard, dir, mode, previous, next, dryRun)
+		return err
+	}
+	v1, err := semver.Parse("v1.4.2")
+	require.NoError(t, err)
+	v2, err := semver.Parse("v2.0.0")
+	require.NoError(t, err)
+	v1Next, err := semver.Parse("v1.5.0")
+	require.NoError(t, err)
+
+	files := map[string]string{"go.mod": "module example.com/mod\n\ngo 1.22\n"}
+
+	t.Run("no go.mod", func(t *testing.T) {
+		dir := writeModule(t, map[string]string{})
+		assert.NoError(t, checkGoModule(dir, internal.GO_MODULE_ERROR, v1, v2, false))
+	})
+
+	t.Run("same major", func(t *testing.T) {
+		dir := writeModule(t, files)
+		assert.NoError(t, checkGoModule(dir, internal.GO_MODULE_ERROR, v1, v1Next, false))
+	})
+
+	t.Run("error", func(t *testing.T) {
+		dir := writeModule(t, files)
+		err := checkGoModule(dir, internal.GO_MODULE_ERROR, v1, v2, false)
+		assert.ErrorContains(t, err, "example.com/mod/v2")
+	})
+
+	t.Run("suffix present", func(t *testing.T) {
+		dir := writeModule(t, map[string]string{"go.mod": "module example.com/mod/v2\n"})
+		assert.NoError(t, checkGoModule(dir, internal.GO_MODULE_ERROR, v1, v2, false))
+	})
+
+	t.Run("warn and ignore", func(t *testing.T) {
+		dir := writeModule(t, files)
+		assert.NoError(t, checkGoModule(dir, internal.GO_MODULE_WARN, v1, v2, false))
+		assert.NoError(t, checkGoModule(dir, internal.GO_MODULE_IGNORE, v1, v2, false))
+	})
+
+	t.Run("invalid mode", func(t *testing.T) {
+		dir := writeModule(t, files)
+		assert.Error(t, checkGoModule(dir, "foo", v1, v2, false))
+	})
+
+	t.Run("rewrite", func(t *testing.T) {
+		dir := writeModule(t, map[string]string{
+			"go.mod": "module example.com/mod\n\ngo 1.22\n",
+			"main.go": `package main
+
+import (
+	"fmt"
+
+	"example.com/mod/pkg"
+	other "example.com/mod/pkg/sub"
+	"example.com/module"
+)
+
+func main() { fmt.Println(pkg.X, other.Y, module.Z, "example.com/mod/pkg") }
+`,
+			"vendor/example.com/dep/dep.go": "package dep\n\nimport \"example.com/mod/pkg\"\n",
+			"nested/go.mod":                 "module example.com/nested\n",
+			"nested/nested.go":              "package nested\n\nimport \"example.com/mod/pkg\"\n",
+		})
+
+		restore, err := internal.CheckGoModule(discard, dir, internal.GO_MODULE_REWRITE, v1, v2, false)
+		require.NoError(t, err)
+
+		content, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+		require.NoError(t, err)
+		assert.Equal(t, "module example.com/mod/v2\n\ngo 1.22\n", string(content))
+
+		content, err = os.ReadFile(filepath.Join(dir, "main.go"))
+		require.NoError(t, err)
+		assert.Equal(t, `package main
+
+import (
+	"fmt"
+
+	"example.com/mod/v2/pkg"
+	other "example.com/mod/v2/pkg/sub"
+	"example.com/module"
+)
+
+func main() { fmt.Println(pkg.X, other.Y, module.Z, "example.com/mod/pkg") }
+`, string(content))
+
+		for _, unchanged := range []string{"vendor/example.com/dep/dep.go", "nested/nested.go"} {
+			content, err = os.ReadFile(filepath.Join(dir, unchanged))
+			require.NoError(t, err)
+			assert.Contains(t, string(content), `"example.com/mod/pkg"`, unchanged)
+		}
+
+		require.NoError(t, restore())
+		content, err = os.ReadFile(filepath.Join(dir, "main.go"))
+		require.NoError(t, err)
+		assert.Contains(t, string(content), `"example.com/mod/pkg"`)
+		content, err = os.ReadFile(filepath.Join(dir, "go.mod"))
+		require.NoError(t, err)
+		assert.Equal(t, files["go.mod"], string(content))
+	})
+
+	t.Run("rewrite dry run", func(t *testing.T) {
+		dir := writeModule(t, files)
+		require.NoError(t, checkGoModule(dir, internal.GO_MODULE_REWRITE, v1, v2, true))
+
+		content, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+		require.NoError(t, err)
+		assert.Equal(t, files["go.mod"], string(content))
+	})
+
+	t.Run("sub directory module", func(t *testing.T) {
+		dir := writeModule(t, map[string]string{"tools/go.mod": "module example.com/mod/tools\n"})
+		prev, err := semver.Parse("tools/v1.0.0")
+		require.NoError(t, err)
+		next, err := semver.Parse("tools/v2.0.0")
+		require.NoError(t, err)
+
+		err = checkGoModule(dir, internal.GO_MODULE_ERROR, prev, next, false)
+		assert.ErrorContains(t, err, "example.com/mod/tools/v2")
+	})
+}
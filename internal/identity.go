@@ -0,0 +1,106 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	AUTHOR_NAME_ENV     = "BUMP_AUTHOR_NAME"
+	AUTHOR_EMAIL_ENV    = "BUMP_AUTHOR_EMAIL"
+	COMMITTER_NAME_ENV  = "BUMP_COMMITTER_NAME"
+	COMMITTER_EMAIL_ENV = "BUMP_COMMITTER_EMAIL"
+)
+
+type Identity struct {
+	Name  string `json:"name" description:"Name of the identity"`
+	Email string `json:"email" description:"Email of the identity"`
+}
+
+func (i Identity) String() string {
+	return fmt.Sprintf("%s <%s>", i.Name, i.Email)
+}
+
+func (i Identity) IsComplete() bool {
+	return i.Name != "" && i.Email != ""
+}
+
+// GitIdentities are the identities configured in the git config
+type GitIdentities struct {
+	User      Identity
+	Author    Identity
+	Committer Identity
+}
+
+// ResolveIdentities resolves the commit author and committer. Each field is taken from
+// the first of the environment, the bump config and the git config that sets it.
+// The committer falls back to the author.
+func ResolveIdentities(config *Config, git GitIdentities, getenv func(string) string) (author Identity, committer Identity) {
+	var configAuthor, configCommitter Identity
+	if config != nil && config.Author != nil {
+		configAuthor = *config.Author
+	}
+	if config != nil && config.Committer != nil {
+		configCommitter = *config.Committer
+	}
+
+	author = mergeIdentities(
+		Identity{Name: getenv(AUTHOR_NAME_ENV), Email: getenv(AUTHOR_EMAIL_ENV)},
+		configAuthor,
+		git.Author,
+		git.User,
+	)
+	committer = mergeIdentities(
+		Identity{Name: getenv(COMMITTER_NAME_ENV), Email: getenv(COMMITTER_EMAIL_ENV)},
+		configCommitter,
+		git.Committer,
+		git.User,
+		author,
+	)
+	return author, committer
+}
+
+func mergeIdentities(identities ...Identity) Identity {
+	merged := Identity{}
+	for _, i := range identities {
+		if merged.Name == "" {
+			merged.Name = i.Name
+		}
+		if merged.Email == "" {
+			merged.Email = i.Email
+		}
+	}
+	return merged
+}
+
+// CheckIdentity returns an error describing how to configure the identity if it is incomplete
+func CheckIdentity(role string, i Identity) error {
+	if i.IsComplete() {
+		return nil
+	}
+	env := strings.ToUpper(role)
+	return fmt.Errorf(
+		"%s identity is incomplete (name: %q, email: %q), set %q in %s, BUMP_%s_NAME and BUMP_%s_EMAIL, or git config user.name and user.email",
+		role, i.Name, i.Email, role, CONFIG_FILE, env, env,
+	)
+}
+
+// CommitTrailers returns the Co-authored-by and Signed-off-by trailers for a commit message
+func CommitTrailers(signOff *Identity, coAuthors []string) []string {
+	trailers := []string{}
+	for _, coAuthor := range coAuthors {
+		trailers = append(trailers, "Co-authored-by: "+coAuthor)
+	}
+	if signOff != nil {
+		trailers = append(trailers, "Signed-off-by: "+signOff.String())
+	}
+	return trailers
+}
+
+// AddTrailers appends trailers to a commit message
+func AddTrailers(message string, trailers ...string) string {
+	if len(trailers) == 0 {
+		return message
+	}
+	return strings.TrimRight(message, "\n") + "\n\n" + strings.Join(trailers, "\n")
+}
@@ -0,0 +1,97 @@
+package internal_test
+
+import (
+	"testing"
+
+	"github.com/mrvinkel/bump/internal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveIdentities(t *testing.T) {
+	type test struct {
+		name      string
+		config    *internal.Config
+		git       internal.GitIdentities
+		env       map[string]string
+		author    internal.Identity
+		committer internal.Identity
+	}
+
+	gitUser := internal.GitIdentities{User: internal.Identity{Name: "Git User", Email: "git@example.com"}}
+
+	tests := []test{
+		{
+			name:      "nothing configured",
+			author:    internal.Identity{},
+			committer: internal.Identity{},
+		},
+		{
+			name:      "git user",
+			git:       gitUser,
+			author:    internal.Identity{Name: "Git User", Email: "git@example.com"},
+			committer: internal.Identity{Name: "Git User", Email: "git@example.com"},
+		},
+		{
+			name: "config overrides git",
+			config: &internal.Config{
+				Author: &internal.Identity{Name: "Release Bot", Email: "bot@example.com"},
+			},
+			git:       gitUser,
+			author:    internal.Identity{Name: "Release Bot", Email: "bot@example.com"},
+			committer: internal.Identity{Name: "Git User", Email: "git@example.com"},
+		},
+		{
+			name: "committer falls back to author",
+			config: &internal.Config{
+				Author: &internal.Identity{Name: "Release Bot", Email: "bot@example.com"},
+			},
+			author:    internal.Identity{Name: "Release Bot", Email: "bot@example.com"},
+			committer: internal.Identity{Name: "Release Bot", Email: "bot@example.com"},
+		},
+		{
+			name: "env overrides config per field",
+			config: &internal.Config{
+				Author:    &internal.Identity{Name: "Release Bot", Email: "bot@example.com"},
+				Committer: &internal.Identity{Name: "CI", Email: "ci@example.com"},
+			},
+			env: map[string]string{
+				internal.AUTHOR_EMAIL_ENV:   "env@example.com",
+				internal.COMMITTER_NAME_ENV: "Env CI",
+			},
+			author:    internal.Identity{Name: "Release Bot", Email: "env@example.com"},
+			committer: internal.Identity{Name: "Env CI", Email: "ci@example.com"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			getenv := func(key string) string { return tc.env[key] }
+
+			author, committer := internal.ResolveIdentities(tc.config, tc.git, getenv)
+
+			assert.Equal(t, tc.author, author)
+			assert.Equal(t, tc.committer, committer)
+		})
+	}
+}
+
+func TestCheckIdentity(t *testing.T) {
+	assert.NoError(t, internal.CheckIdentity("author", internal.Identity{Name: "a", Email: "a@example.com"}))
+
+	err := internal.CheckIdentity("author", internal.Identity{Name: "a"})
+	assert.ErrorContains(t, err, "BUMP_AUTHOR_EMAIL")
+}
+
+func TestAddTrailers(t *testing.T) {
+	signOff := &internal.Identity{Name: "Jane Doe", Email: "jane@example.com"}
+
+	assert.Equal(t, "release v1.0.0", internal.AddTrailers("release v1.0.0", internal.CommitTrailers(nil, nil)...))
+	assert.Equal(t,
+		"release v1.0.0\n\nSigned-off-by: Jane Doe <jane@example.com>",
+		internal.AddTrailers("release v1.0.0\n", internal.CommitTrailers(signOff, nil)...),
+	)
+	assert.Equal(t,
+		"release v1.0.0\n\nCo-authored-by: John Doe <john@example.com>\nSigned-off-by: Jane Doe <jane@example.com>",
+		internal.AddTrailers("release v1.0.0", internal.CommitTrailers(signOff, []string{"John Doe <john@example.com>"})...),
+	)
+}
@@ -0,0 +1,468 @@
+package internal
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/mrvinkel/bump/semver"
+	"github.com/spf13/cobra"
+)
+
+const (
+	SCHEMA_URL = "https://raw.githubusercontent.com/MrVinkel/bump/refs/%s/bump.schema.json"
+
+	DEFAULT_PREFIX = "v"
+)
+
+var (
+	pyprojectPoetry  = regexp.MustCompile(`(?m)^\[tool\.poetry\]`)
+	pyprojectDynamic = regexp.MustCompile(`(?m)^dynamic\s*=.*"version"`)
+	flakeVersion     = regexp.MustCompile(`version\s*=\s*"[^"]*"\s*;`)
+)
+
+// Project is a project type detected from a file in the root of the repository
+type Project struct {
+	Name string
+	File string
+	// Scheme is the versioning scheme of the project type, empty for any
+	Scheme string
+	// Hook is a pre-hook updating the version in File, with %s for the version without
+	// the prefix. %s expands to a variable, so it must not be in single quotes. Empty if
+	// the version is not kept in a file.
+	Hook string
+	// Note explains the hook, or why there is none
+	Note string
+}
+
+// projectTypes detect a project type from the content of its file, in order of
+// precedence for the versioning scheme
+var projectTypes = []struct {
+	file    string
+	project func(content string) Project
+}{
+	{GO_MOD_FILE, func(string) Project {
+		return Project{Name: "Go module", Scheme: SCHEME_GO, Note: "Go modules are versioned by the tags only"}
+	}},
+	{"pyproject.toml", func(content string) Project {
+		p := Project{Name: "Python project", Scheme: SCHEME_PEP440}
+		switch {
+		case pyprojectDynamic.MatchString(content):
+			p.Note = "the version is dynamic, read from the tags"
+		case pyprojectPoetry.MatchString(content):
+			p.Hook = `poetry version "%s"`
+		default:
+			p.Hook = `sed -i -E 's/^version = ".*"/version = "'"%s"'"/' pyproject.toml`
+		}
+		return p
+	}},
+	{"package.json", func(string) Project {
+		return Project{Name: "npm package", Hook: `npm version --no-git-tag-version --allow-same-version "%s"`}
+	}},
+	{"Cargo.toml", func(string) Project {
+		return Project{Name: "Rust crate", Hook: `cargo set-version "%s"`, Note: "needs cargo-edit"}
+	}},
+	{"Chart.yaml", func(string) Project {
+		return Project{Name: "Helm chart", Hook: `sed -i -E 's/^version:.*/version: '"%s"'/' Chart.yaml`}
+	}},
+	{"flake.nix", func(content string) Project {
+		p := Project{Name: "Nix flake"}
+		if flakeVersion.MatchString(content) {
+			p.Hook = `sed -i -E 's/version = "[^"]*";/version = "'"%s"'";/' flake.nix`
+		} else {
+			p.Note = "no version attribute found"
+		}
+		return p
+	}},
+}
+
+// InitConfig is the config proposed by bump init
+type InitConfig struct {
+	Projects []Project
+	Scheme   string
+	Prefix   string
+	// Hooks are the projects whose hooks are added as pre-hooks
+	Hooks []Project
+}
+
+// DetectProjects detects the project types by the files in the root of fsys
+func DetectProjects(fsys fs.FS) ([]Project, error) {
+	projects := []Project{}
+	for _, t := range projectTypes {
+		content, err := fs.ReadFile(fsys, t.file)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		p := t.project(string(content))
+		p.File = t.file
+		projects = append(projects, p)
+	}
+	return projects, nil
+}
+
+// InferPrefix returns the most common prefix of the version tags, or def if there are
+// no version tags
+func InferPrefix(tags []string, def string) string {
+	counts := map[string]int{}
+	for _, tag := range tags {
+		var prefix *string
+		if v, err := semver.Parse(tag); err == nil {
+			prefix = v.Prefix
+		} else if v, err := ParsePEP440Version(tag); err == nil {
+			prefix = v.Prefix
+		} else {
+			continue
+		}
+		if prefix == nil {
+			counts[""]++
+		} else {
+			counts[*prefix]++
+		}
+	}
+	if len(counts) == 0 {
+		return def
+	}
+
+	prefixes := make([]string, 0, len(counts))
+	for prefix := range counts {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Slice(prefixes, func(i, j int) bool {
+		if counts[prefixes[i]] != counts[prefixes[j]] {
+			return counts[prefixes[i]] > counts[prefixes[j]]
+		}
+		return prefixes[i] < prefixes[j]
+	})
+	return prefixes[0]
+}
+
+// ProposeConfig proposes a config for the projects in the repository and its tags
+func ProposeConfig(projects []Project, tags []string) InitConfig {
+	c := InitConfig{Projects: projects, Scheme: SCHEME_SEMVER, Prefix: InferPrefix(tags, DEFAULT_PREFIX)}
+	for _, p := range projects {
+		if p.Scheme != "" {
+			c.Scheme = p.Scheme
+			break
+		}
+	}
+	if c.Scheme == SCHEME_GO && c.Prefix != "v" && !strings.HasSuffix(c.Prefix, "/v") {
+		c.Scheme = SCHEME_SEMVER
+	}
+	for _, p := range projects {
+		if p.Hook != "" {
+			c.Hooks = append(c.Hooks, p)
+		}
+	}
+	return c
+}
+
+// HookCommand returns the pre-hook of a project for the prefix
+func HookCommand(p Project, prefix string) string {
+	version := "$VERSION"
+	if prefix != "" {
+		version = "${VERSION#" + prefix + "}"
+	}
+	return fmt.Sprintf(p.Hook, version)
+}
+
+// SchemaURL returns the URL of the JSON schema for this version of bump
+func SchemaURL() string {
+	if BumpVersion == "dev" {
+		return fmt.Sprintf(SCHEMA_URL, "heads/main")
+	}
+	return fmt.Sprintf(SCHEMA_URL, "tags/"+BumpVersion)
+}
+
+// initField is a field of the generated config with a comment
+type initField struct {
+	comment string
+	key     string
+	value   string
+	// items of a list, value is not used if set
+	items []initField
+}
+
+func (c InitConfig) fields() []initField {
+	names := []string{}
+	for _, p := range c.Projects {
+		names = append(names, fmt.Sprintf("%s (%s)", p.Name, p.File))
+	}
+	header := "bump config, see https://github.com/mrvinkel/bump"
+	if len(names) > 0 {
+		header += "\nDetected " + SliceString(names)
+	}
+
+	hooks := []initField{}
+	for _, p := range c.Hooks {
+		comment := p.File
+		if p.Note != "" {
+			comment += ", " + p.Note
+		}
+		hooks = append(hooks, initField{comment: comment, value: quote(HookCommand(p, c.Prefix))})
+	}
+
+	return []initField{
+		{comment: header, key: "$schema", value: quote(SchemaURL())},
+		{comment: "Versioning scheme of the tags, one of " + SliceString(schemeNames()), key: "scheme", value: quote(c.Scheme)},
+		{comment: "Prefix of the version tags", key: "prefix", value: quote(c.Prefix)},
+		{comment: "Message of the release commit", key: "message", value: quote("release {{.Version}}")},
+		{comment: "Commands to run before tagging, $VERSION is the new version. Changes are committed", key: "preHook", items: hooks},
+	}
+}
+
+func quote(s string) string {
+	b := strings.Builder{}
+	encoder := json.NewEncoder(&b)
+	encoder.SetEscapeHTML(false)
+	_ = encoder.Encode(s)
+	return strings.TrimSpace(b.String())
+}
+
+func schemeNames() []string {
+	for _, f := range configFields(reflect.TypeFor[Config]()) {
+		if f.Name == "scheme" {
+			return f.Enum
+		}
+	}
+	return nil
+}
+
+func writeComment(b *strings.Builder, indent, marker, comment string) {
+	for _, line := range strings.Split(comment, "\n") {
+		fmt.Fprintf(b, "%s%s %s\n", indent, marker, line)
+	}
+}
+
+// YAML returns the config as commented YAML
+func (c InitConfig) YAML() []byte {
+	b := strings.Builder{}
+	for i, f := range c.fields() {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		writeComment(&b, "", "#", f.comment)
+		switch {
+		case f.items == nil:
+			fmt.Fprintf(&b, "%s: %s\n", f.key, f.value)
+		case len(f.items) == 0:
+			fmt.Fprintf(&b, "# %s:\n#   - make release\n", f.key)
+		default:
+			fmt.Fprintf(&b, "%s:\n", f.key)
+			for _, item := range f.items {
+				writeComment(&b, "  ", "#", item.comment)
+				fmt.Fprintf(&b, "  - %s\n", item.value)
+			}
+		}
+	}
+	return []byte(b.String())
+}
+
+// JSON returns the config as JSON with comments
+func (c InitConfig) JSON() []byte {
+	fields := c.fields()
+	b := strings.Builder{}
+	b.WriteString("{\n")
+	for i, f := range fields {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		comma := ","
+		if i == len(fields)-1 {
+			comma = ""
+		}
+		writeComment(&b, "  ", "//", f.comment)
+		if f.items == nil {
+			fmt.Fprintf(&b, "  %s: %s%s\n", quote(f.key), f.value, comma)
+			continue
+		}
+		fmt.Fprintf(&b, "  %s: [", quote(f.key))
+		if len(f.items) == 0 {
+			b.WriteString("]" + comma + "\n")
+			continue
+		}
+		b.WriteString("\n")
+		for j, item := range f.items {
+			itemComma := ","
+			if j == len(f.items)-1 {
+				itemComma = ""
+			}
+			writeComment(&b, "    ", "//", item.comment)
+			fmt.Fprintf(&b, "    %s%s\n", item.value, itemComma)
+		}
+		b.WriteString("  ]" + comma + "\n")
+	}
+	b.WriteString("}\n")
+	return []byte(b.String())
+}
+
+// prompt asks a question and returns the answer, or def if the answer is empty
+func prompt(in *bufio.Reader, out io.Writer, question, def string) (string, error) {
+	if _, err := fmt.Fprintf(out, "%s [%s]: ", question, def); err != nil {
+		return "", err
+	}
+	answer, err := in.ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	if answer = strings.TrimSpace(answer); answer == "" {
+		return def, nil
+	}
+	return answer, nil
+}
+
+// confirm asks a yes or no question, yes by default
+func confirm(in *bufio.Reader, out io.Writer, question string) (bool, error) {
+	for {
+		answer, err := prompt(in, out, question, "Y/n")
+		if err != nil {
+			return false, err
+		}
+		switch strings.ToLower(answer) {
+		case "y/n", "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
+		}
+		if _, err = fmt.Fprintln(out, "answer y or n"); err != nil {
+			return false, err
+		}
+	}
+}
+
+// AskConfig lets the user change the proposed scheme and prefix and choose the pre-hooks
+func AskConfig(c InitConfig, in *bufio.Reader, out io.Writer) (InitConfig, error) {
+	for _, p := range c.Projects {
+		line := fmt.Sprintf("detected %s (%s)", p.Name, p.File)
+		if p.Note != "" {
+			line += ", " + p.Note
+		}
+		if _, err := fmt.Fprintln(out, line); err != nil {
+			return c, err
+		}
+	}
+
+	for {
+		scheme, err := prompt(in, out, "Versioning scheme, "+SliceString(schemeNames()), c.Scheme)
+		if err != nil {
+			return c, err
+		}
+		if slices.Contains(schemeNames(), scheme) {
+			c.Scheme = scheme
+			break
+		}
+		if _, err = fmt.Fprintf(out, "unknown scheme %q\n", scheme); err != nil {
+			return c, err
+		}
+	}
+
+	prefix, err := prompt(in, out, "Tag prefix", c.Prefix)
+	if err != nil {
+		return c, err
+	}
+	c.Prefix = prefix
+
+	hooks := []Project{}
+	for _, p := range c.Hooks {
+		ok, err := confirm(in, out, fmt.Sprintf("Update the version in %s with: %s", p.File, HookCommand(p, c.Prefix)))
+		if err != nil {
+			return c, err
+		}
+		if ok {
+			hooks = append(hooks, p)
+		}
+	}
+	c.Hooks = hooks
+	return c, nil
+}
+
+// Init writes a commented config for the repository. The versioning scheme and
+// pre-hooks are proposed from the project files and the prefix from the tags.
+func Init(cmd *cobra.Command, args []string) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	repo, err := NewRepo(cwd)
+	if err != nil {
+		return err
+	}
+
+	repoDir, err := repo.GetDir()
+	if err != nil {
+		return err
+	}
+
+	existing, err := findConfigFile(os.DirFS(repoDir), CONFIG_FILES)
+	if err != nil {
+		return err
+	}
+	if existing != "" {
+		return fmt.Errorf("%s already exists", existing)
+	}
+
+	projects, err := DetectProjects(os.DirFS(repoDir))
+	if err != nil {
+		return err
+	}
+	tags, err := repo.GetTags()
+	if err != nil {
+		return err
+	}
+	config := ProposeConfig(projects, tags)
+
+	yes, err := cmd.Flags().GetBool("yes")
+	if err != nil {
+		return err
+	}
+	writeJSON, err := cmd.Flags().GetBool("json")
+	if err != nil {
+		return err
+	}
+	dryRun, err := cmd.Flags().GetBool("dry-run")
+	if err != nil {
+		return err
+	}
+
+	in := bufio.NewReader(cmd.InOrStdin())
+	out := cmd.OutOrStdout()
+	if !yes {
+		if config, err = AskConfig(config, in, out); err != nil {
+			return err
+		}
+	}
+
+	name, content := CONFIG_FILE_YAML, config.YAML()
+	if writeJSON {
+		name, content = CONFIG_FILE, config.JSON()
+	}
+
+	if dryRun {
+		Info("dry run, will not write config", "file", name)
+		_, err = out.Write(content)
+		return err
+	}
+	if !yes {
+		ok, err := confirm(in, out, "Write "+name)
+		if err != nil || !ok {
+			return err
+		}
+	}
+	if err = os.WriteFile(filepath.Join(repoDir, name), content, 0o644); err != nil {
+		return err
+	}
+	Info("wrote config", "file", name)
+	return nil
+}
@@ -0,0 +1,206 @@
+package internal_test
+
+import (
+	"bufio"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/mrvinkel/bump/internal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectProjects(t *testing.T) {
+	fs := fstest.MapFS{
+		"go.mod":         &fstest.MapFile{Data: []byte("module example.com/mod\n")},
+		"pyproject.toml": &fstest.MapFile{Data: []byte("[project]\nname = \"mod\"\ndynamic = [\"version\"]\n")},
+		"Cargo.toml":     &fstest.MapFile{Data: []byte("[package]\nversion = \"0.1.0\"\n")},
+		"flake.nix":      &fstest.MapFile{Data: []byte("{ outputs = { self }: { }; }\n")},
+		"readme.md":      &fstest.MapFile{Data: []byte("# mod\n")},
+	}
+
+	projects, err := internal.DetectProjects(fs)
+	require.NoError(t, err)
+
+	files := []string{}
+	for _, p := range projects {
+		files = append(files, p.File)
+	}
+	assert.Equal(t, []string{"go.mod", "pyproject.toml", "Cargo.toml", "flake.nix"}, files)
+	assert.Equal(t, internal.SCHEME_GO, projects[0].Scheme)
+	// dynamic versions and flakes without a version need no hook
+	assert.Empty(t, projects[1].Hook)
+	assert.Equal(t, `cargo set-version "%s"`, projects[2].Hook)
+	assert.Empty(t, projects[3].Hook)
+}
+
+func TestInferPrefix(t *testing.T) {
+	type test struct {
+		tags []string
+		want string
+	}
+
+	tests := []test{
+		{tags: nil, want: "v"},
+		{tags: []string{"latest", "nightly"}, want: "v"},
+		{tags: []string{"1.0.0", "1.1.0", "v0.1.0"}, want: ""},
+		{tags: []string{"api-v1.0.0", "api-v1.1.0", "v1.0.0"}, want: "api-v"},
+		{tags: []string{"release-1.0a1"}, want: "release-"},
+		// ties are broken alphabetically
+		{tags: []string{"b1.0.0", "a1.0.0"}, want: "a"},
+	}
+
+	for _, tc := range tests {
+		t.Run(strings.Join(tc.tags, ","), func(t *testing.T) {
+			assert.Equal(t, tc.want, internal.InferPrefix(tc.tags, "v"))
+		})
+	}
+}
+
+func TestProposeConfig(t *testing.T) {
+	projects := []internal.Project{
+		{Name: "Go module", File: "go.mod", Scheme: internal.SCHEME_GO},
+		{Name: "npm package", File: "package.json", Hook: `npm version "%s"`},
+	}
+
+	c := internal.ProposeConfig(projects, []string{"v1.0.0"})
+	assert.Equal(t, internal.SCHEME_GO, c.Scheme)
+	assert.Equal(t, "v", c.Prefix)
+	assert.Equal(t, projects[1:], c.Hooks)
+	assert.Equal(t, `npm version "${VERSION#v}"`, internal.HookCommand(c.Hooks[0], c.Prefix))
+
+	// go module tags must have the prefix v
+	c = internal.ProposeConfig(projects, []string{"release-1.0.0"})
+	assert.Equal(t, internal.SCHEME_SEMVER, c.Scheme)
+	assert.Equal(t, `npm version "$VERSION"`, internal.HookCommand(c.Hooks[0], ""))
+}
+
+// runHook runs a pre-hook in dir with the version and returns the content of the file
+func runHook(t *testing.T, dir, hook, version, file string) string {
+	t.Helper()
+	cmd := exec.Command("/bin/bash", "-c", hook)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "VERSION="+version)
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+
+	content, err := os.ReadFile(filepath.Join(dir, file))
+	require.NoError(t, err)
+	return string(content)
+}
+
+func TestHookCommand(t *testing.T) {
+	type test struct {
+		file     string
+		content  string
+		prefix   string
+		expected string
+	}
+
+	tests := map[string]test{
+		"pyproject": {
+			file:     "pyproject.toml",
+			content:  "[project]\nname = \"mod\"\nversion = \"0.1.0\"\n",
+			prefix:   "v",
+			expected: "[project]\nname = \"mod\"\nversion = \"1.2.3\"\n",
+		},
+		"pyproject without prefix": {
+			file:     "pyproject.toml",
+			content:  "[project]\nversion = \"0.1.0\"\n",
+			expected: "[project]\nversion = \"1.2.3\"\n",
+		},
+		"helm chart": {
+			file:     "Chart.yaml",
+			content:  "apiVersion: v2\nname: mod\nversion: 0.1.0\n",
+			prefix:   "v",
+			expected: "apiVersion: v2\nname: mod\nversion: 1.2.3\n",
+		},
+		"nix flake": {
+			file:     "flake.nix",
+			content:  "{\n  outputs = { self }: { version = \"0.1.0\"; };\n}\n",
+			prefix:   "release-",
+			expected: "{\n  outputs = { self }: { version = \"1.2.3\"; };\n}\n",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			projects, err := internal.DetectProjects(fstest.MapFS{tc.file: &fstest.MapFile{Data: []byte(tc.content)}})
+			require.NoError(t, err)
+			require.Len(t, projects, 1)
+
+			dir := t.TempDir()
+			require.NoError(t, os.WriteFile(filepath.Join(dir, tc.file), []byte(tc.content), 0o644))
+			hook := internal.HookCommand(projects[0], tc.prefix)
+			assert.Equal(t, tc.expected, runHook(t, dir, hook, tc.prefix+"1.2.3", tc.file))
+		})
+	}
+}
+
+func TestInitConfigFormats(t *testing.T) {
+	c := internal.InitConfig{
+		Projects: []internal.Project{{Name: "Python project", File: "pyproject.toml"}},
+		Scheme:   internal.SCHEME_PEP440,
+		Prefix:   "v",
+	}
+	pyproject := "[project]\nname = \"mod\"\nversion = \"0.1.0\"\n"
+	projects, err := internal.DetectProjects(fstest.MapFS{"pyproject.toml": &fstest.MapFile{Data: []byte(pyproject)}})
+	require.NoError(t, err)
+	c.Hooks = projects
+
+	for file, data := range map[string][]byte{internal.CONFIG_FILE: c.JSON(), internal.CONFIG_FILE_YAML: c.YAML()} {
+		t.Run(file, func(t *testing.T) {
+			assert.Contains(t, string(data), "Detected Python project (pyproject.toml)")
+
+			config, err := internal.ReadConfig(fstest.MapFS{file: &fstest.MapFile{Data: data}})
+			require.NoError(t, err)
+
+			assert.Equal(t, internal.SchemaURL(), *config.Schema)
+			assert.Equal(t, internal.SCHEME_PEP440, *config.Scheme)
+			assert.Equal(t, "v", *config.Prefix)
+			require.Len(t, config.PreHook, 1)
+			dir := t.TempDir()
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "pyproject.toml"), []byte(pyproject), 0o644))
+			assert.Equal(t, "[project]\nname = \"mod\"\nversion = \"1.2.3\"\n", runHook(t, dir, config.PreHook[0], "v1.2.3", "pyproject.toml"))
+		})
+	}
+
+	// without hooks
+	c.Hooks = nil
+	for file, data := range map[string][]byte{internal.CONFIG_FILE: c.JSON(), internal.CONFIG_FILE_YAML: c.YAML()} {
+		config, err := internal.ReadConfig(fstest.MapFS{file: &fstest.MapFile{Data: data}})
+		require.NoError(t, err, file)
+		assert.Empty(t, config.PreHook, file)
+	}
+}
+
+func TestAskConfig(t *testing.T) {
+	c := internal.InitConfig{
+		Scheme: internal.SCHEME_SEMVER,
+		Prefix: "v",
+		Hooks: []internal.Project{
+			{File: "package.json", Hook: `npm version "%s"`},
+			{File: "Chart.yaml", Hook: `sed -i 's/^version:.*/version: '"%s"'/' Chart.yaml`},
+		},
+	}
+	in := bufio.NewReader(strings.NewReader("semantic\npep440\n\nmaybe\nn\nn\n"))
+	out := strings.Builder{}
+
+	c, err := internal.AskConfig(c, in, &out)
+	require.NoError(t, err)
+
+	assert.Equal(t, internal.SCHEME_PEP440, c.Scheme)
+	assert.Equal(t, "v", c.Prefix)
+	assert.Empty(t, c.Hooks)
+	assert.Contains(t, out.String(), `unknown scheme "semantic"`)
+	assert.Contains(t, out.String(), "answer y or n")
+
+	// end of input accepts the proposal
+	c, err = internal.AskConfig(internal.InitConfig{Scheme: internal.SCHEME_GO, Prefix: "v", Hooks: c.Hooks}, bufio.NewReader(strings.NewReader("")), &out)
+	require.NoError(t, err)
+	assert.Equal(t, internal.SCHEME_GO, c.Scheme)
+}
@@ -0,0 +1,219 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// log formats
+const (
+	LOG_FORMAT_PLAIN = "plain"
+	LOG_FORMAT_TEXT  = "text"
+	LOG_FORMAT_JSON  = "json"
+)
+
+var LOG_FORMATS = []string{LOG_FORMAT_PLAIN, LOG_FORMAT_TEXT, LOG_FORMAT_JSON}
+
+var (
+	// logLevel is the level of the console log, see SetLogLevel
+	logLevel = &slog.LevelVar{}
+
+	logger              = slog.New(newPlainHandler(os.Stderr, logLevel))
+	logOutput io.Writer = os.Stderr
+	logFormat           = LOG_FORMAT_PLAIN
+	logFile   *os.File
+)
+
+// SetLogLevel sets the level of the console log from --debug and --quiet. Commands
+// log at the level of their config instead, see commandLogger.
+func SetLogLevel(debug, quiet bool) {
+	logLevel.Set(levelOf(debug, quiet))
+}
+
+// levelOf returns the console level of debug and quiet. Debug wins over quiet.
+func levelOf(debug, quiet bool) slog.Level {
+	switch {
+	case debug:
+		return slog.LevelDebug
+	case quiet:
+		return slog.LevelWarn
+	}
+	return slog.LevelInfo
+}
+
+// SetupLogging sends the log to w in the format. If file is not empty every record,
+// including debug records, is also appended to it with a timestamp, as json if the
+// format is json and as text otherwise.
+func SetupLogging(w io.Writer, format, file string) error {
+	if format == "" {
+		format = LOG_FORMAT_PLAIN
+	}
+	if !slices.Contains(LOG_FORMATS, format) {
+		return fmt.Errorf("invalid log format %q, must be one of %s", format, SliceString(LOG_FORMATS))
+	}
+
+	if logFile != nil {
+		logFile.Close() // nolint:errcheck
+		logFile = nil
+	}
+	if file != "" {
+		f, err := os.OpenFile(file, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return fmt.Errorf("unable to open log file: %w", err)
+		}
+		logFile = f
+	}
+
+	logOutput = w
+	logFormat = format
+	logger = newLogger(logLevel)
+	return nil
+}
+
+// newLogger returns a logger with the output, format and file of SetupLogging, with
+// the console at the level
+func newLogger(level slog.Leveler) *slog.Logger {
+	var handler slog.Handler
+	switch logFormat {
+	case LOG_FORMAT_TEXT:
+		handler = slog.NewTextHandler(logOutput, &slog.HandlerOptions{Level: level})
+	case LOG_FORMAT_JSON:
+		handler = slog.NewJSONHandler(logOutput, &slog.HandlerOptions{Level: level})
+	default:
+		handler = newPlainHandler(logOutput, level)
+	}
+
+	if logFile != nil {
+		options := &slog.HandlerOptions{Level: slog.LevelDebug}
+		if logFormat == LOG_FORMAT_JSON {
+			handler = slog.NewMultiHandler(handler, slog.NewJSONHandler(logFile, options))
+		} else {
+			handler = slog.NewMultiHandler(handler, slog.NewTextHandler(logFile, options))
+		}
+	}
+	return slog.New(handler)
+}
+
+// commandLogger returns the logger of a command with the resolved config. Debug and
+// quiet in the config set its level, which leaves the level of the package alone.
+func commandLogger(config *Config) *slog.Logger {
+	if config == nil || (config.Debug == nil && config.Quiet == nil) {
+		return logger
+	}
+	level := levelOf(config.Debug != nil && *config.Debug, config.Quiet != nil && *config.Quiet)
+	return newLogger(level)
+}
+
+// Debug logs details of a run, shown with --debug. args are key value pairs as for slog.
+func Debug(msg string, args ...any) {
+	logger.Debug(msg, args...)
+}
+
+// Info logs the progress of a run, hidden by --quiet
+func Info(msg string, args ...any) {
+	logger.Info(msg, args...)
+}
+
+// Warn logs a problem that does not stop the run
+func Warn(msg string, args ...any) {
+	logger.Warn(msg, args...)
+}
+
+// Error logs an error that stops the run
+func Error(msg string, args ...any) {
+	logger.Error(msg, args...)
+}
+
+// plainHandler writes the message and the attributes of a record without time or
+// level, e.g. "tag previous=v1.0.0 version=v1.1.0". Warnings are prefixed with
+// "warning: ".
+type plainHandler struct {
+	w      io.Writer
+	mu     *sync.Mutex
+	level  slog.Leveler
+	attrs  []slog.Attr
+	prefix string
+}
+
+func newPlainHandler(w io.Writer, level slog.Leveler) *plainHandler {
+	return &plainHandler{w: w, mu: &sync.Mutex{}, level: level}
+}
+
+func (h *plainHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+func (h *plainHandler) Handle(_ context.Context, r slog.Record) error {
+	b := strings.Builder{}
+	if r.Level == slog.LevelWarn {
+		b.WriteString("warning: ")
+	}
+	b.WriteString(r.Message)
+	for _, a := range h.attrs {
+		writePlainAttr(&b, "", a)
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		writePlainAttr(&b, h.prefix, a)
+		return true
+	})
+	b.WriteString("\n")
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := io.WriteString(h.w, b.String())
+	return err
+}
+
+func (h *plainHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	for _, a := range attrs {
+		a.Key = h.prefix + a.Key
+		h2.attrs = append(slices.Clip(h2.attrs), a)
+	}
+	return &h2
+}
+
+func (h *plainHandler) WithGroup(name string) slog.Handler {
+	h2 := *h
+	h2.prefix = h.prefix + name + "."
+	return &h2
+}
+
+func writePlainAttr(b *strings.Builder, prefix string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		for _, g := range a.Value.Group() {
+			writePlainAttr(b, prefix+a.Key+".", g)
+		}
+		return
+	}
+	value := a.Value.String()
+	if value == "" || strings.ContainsAny(value, " =\"\t\n") {
+		value = strconv.Quote(value)
+	}
+	fmt.Fprintf(b, " %s%s=%s", prefix, a.Key, value)
+}
+
+func SliceString(s []string) string {
+	first := true
+	b := strings.Builder{}
+	for _, s := range s {
+		if first {
+			first = false
+		} else {
+			b.WriteString(", ")
+		}
+		b.WriteString(s)
+	}
+	return b.String()
+}
@@ -0,0 +1,95 @@
+package internal_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mrvinkel/bump/internal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupLogging(t *testing.T, format, file string, debug, quiet bool) *strings.Builder {
+	t.Helper()
+	internal.SetLogLevel(debug, quiet)
+	w := &strings.Builder{}
+	require.NoError(t, internal.SetupLogging(w, format, file))
+	t.Cleanup(func() {
+		internal.SetLogLevel(false, false)
+		require.NoError(t, internal.SetupLogging(os.Stderr, internal.LOG_FORMAT_PLAIN, ""))
+	})
+	return w
+}
+
+func TestLogPlain(t *testing.T) {
+	type test struct {
+		debug bool
+		quiet bool
+		want  string
+	}
+
+	tests := map[string]test{
+		"default": {want: "tag previous=v1.0.0 version=v1.1.0\nwarning: invalid tag tag=\"release 1\"\nfailed\n"},
+		"quiet":   {quiet: true, want: "warning: invalid tag tag=\"release 1\"\nfailed\n"},
+		// debug wins over quiet
+		"debug": {debug: true, quiet: true, want: "tags tags=\"\"\ntag previous=v1.0.0 version=v1.1.0\nwarning: invalid tag tag=\"release 1\"\nfailed\n"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			w := setupLogging(t, internal.LOG_FORMAT_PLAIN, "", tc.debug, tc.quiet)
+
+			internal.Debug("tags", "tags", "")
+			internal.Info("tag", "previous", "v1.0.0", "version", "v1.1.0")
+			internal.Warn("invalid tag", "tag", "release 1")
+			internal.Error("failed")
+
+			assert.Equal(t, tc.want, w.String())
+		})
+	}
+}
+
+func TestLogFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "bump.log")
+	w := setupLogging(t, internal.LOG_FORMAT_JSON, file, false, true)
+
+	internal.Debug("fetching repository")
+	internal.Info("tag", "version", "v1.1.0")
+	internal.Warn("invalid tag", "tag", "x")
+
+	// the console is quiet
+	lines := strings.Split(strings.TrimSpace(w.String()), "\n")
+	require.Len(t, lines, 1)
+	assert.Contains(t, lines[0], `"level":"WARN","msg":"invalid tag","tag":"x"`)
+
+	// the file gets every record with a timestamp
+	data, err := os.ReadFile(file)
+	require.NoError(t, err)
+	lines = strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 3)
+	record := map[string]any{}
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &record))
+	assert.Equal(t, "DEBUG", record["level"])
+	assert.Equal(t, "fetching repository", record["msg"])
+	assert.NotEmpty(t, record["time"])
+
+	// text is appended
+	setupLogging(t, internal.LOG_FORMAT_PLAIN, file, false, false)
+	internal.Info("tag", "version", "v1.2.0")
+	data, err = os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "level=INFO msg=tag version=v1.2.0\n")
+	assert.Len(t, strings.Split(strings.TrimSpace(string(data)), "\n"), 4)
+}
+
+func TestSetupLoggingInvalid(t *testing.T) {
+	err := internal.SetupLogging(os.Stderr, "xml", "")
+	assert.EqualError(t, err, `invalid log format "xml", must be one of plain, text, json`)
+
+	err = internal.SetupLogging(os.Stderr, internal.LOG_FORMAT_TEXT, filepath.Join(t.TempDir(), "missing", "bump.log"))
+	assert.ErrorContains(t, err, "unable to open log file")
+	require.NoError(t, internal.SetupLogging(os.Stderr, internal.LOG_FORMAT_PLAIN, ""))
+}
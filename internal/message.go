@@ -0,0 +1,159 @@
+package internal
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/mrvinkel/bump/semver"
+)
+
+// legacy ${NAME} variables from before messages were templates
+var legacyVar = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// MessageData is the data available to commit and tag message templates
+type MessageData struct {
+	Version         string
+	PreviousVersion string
+	Prefix          string
+	Major           int
+	Minor           int
+	Patch           int
+	PreRelease      string
+	Build           string
+	Component       string
+	Branch          string
+	Author          string
+	CommitCount     int
+	Date            time.Time
+	Outputs         map[string]string
+}
+
+// NewMessageData returns the message data of a release from newVersion to
+// previousVersion. SemVer numbers too large for an int are errors.
+func NewMessageData(newVersion, previousVersion Versioned, outputs map[string]string) (*MessageData, error) {
+	if outputs == nil {
+		outputs = map[string]string{}
+	}
+	data := &MessageData{
+		Version:         newVersion.String(),
+		PreviousVersion: previousVersion.String(),
+		Date:            time.Now(),
+		Outputs:         outputs,
+	}
+
+	var prefix *string
+	switch v := newVersion.(type) {
+	case *semver.Version:
+		prefix = v.Prefix
+		var err error
+		if data.Major, err = v.Major.Int(); err != nil {
+			return nil, fmt.Errorf("major version %w", err)
+		}
+		if data.Minor, err = v.Minor.Int(); err != nil {
+			return nil, fmt.Errorf("minor version %w", err)
+		}
+		if data.Patch, err = v.Patch.Int(); err != nil {
+			return nil, fmt.Errorf("patch version %w", err)
+		}
+		data.PreRelease = strings.Join(v.PreRelease, ".")
+		if v.Build != nil {
+			data.Build = *v.Build
+		}
+	case *CalVersion:
+		prefix = v.Prefix
+	case *NumericVersion:
+		prefix = new(v.Prefix())
+		// segments the scheme does not have are 0
+		data.Major, _ = v.Segment(LEVEL_MAJOR)
+		data.Minor, _ = v.Segment(LEVEL_MINOR)
+		data.Patch, _ = v.Segment(LEVEL_PATCH)
+	case *PEP440Version:
+		prefix = v.Prefix
+		data.Major = v.Release[0]
+		if len(v.Release) > 1 {
+			data.Minor = v.Release[1]
+		}
+		if len(v.Release) > 2 {
+			data.Patch = v.Release[2]
+		}
+		if v.Local != nil {
+			data.Build = *v.Local
+		}
+	}
+	if prefix != nil {
+		data.Prefix = *prefix
+	}
+	data.Component = Component(data.Prefix)
+	return data, nil
+}
+
+// Component derives the component name from a tag prefix, e.g. "api-v" -> "api"
+func Component(prefix string) string {
+	const separators = "-_/@."
+	component := strings.TrimRight(prefix, separators)
+	if component == "v" {
+		return ""
+	}
+	for _, sep := range separators {
+		if strings.HasSuffix(component, string(sep)+"v") {
+			return strings.TrimRight(strings.TrimSuffix(component, "v"), separators)
+		}
+	}
+	return component
+}
+
+var messageFuncs = template.FuncMap{
+	"upper":      strings.ToUpper,
+	"lower":      strings.ToLower,
+	"trim":       strings.TrimSpace,
+	"trimPrefix": func(prefix, s string) string { return strings.TrimPrefix(s, prefix) },
+	"trimSuffix": func(suffix, s string) string { return strings.TrimSuffix(s, suffix) },
+	"replace":    func(old, new, s string) string { return strings.ReplaceAll(s, old, new) },
+	"date":       func(layout string, t time.Time) string { return t.Format(layout) },
+	"default": func(def string, s string) string {
+		if s == "" {
+			return def
+		}
+		return s
+	},
+}
+
+// RenderMessage renders a commit or tag message template. Legacy ${VERSION},
+// ${PREVIOUS_VERSION} and ${OUTPUT} variables are still supported, the first two in
+// any case, e.g. ${version}. Unknown variables are errors.
+func RenderMessage(message string, data *MessageData) (string, error) {
+	var legacyErr error
+	message = legacyVar.ReplaceAllStringFunc(message, func(s string) string {
+		name := legacyVar.FindStringSubmatch(s)[1]
+		switch strings.ToUpper(name) {
+		case "VERSION":
+			return "{{.Version}}"
+		case "PREVIOUS_VERSION":
+			return "{{.PreviousVersion}}"
+		}
+		if _, ok := data.Outputs[name]; ok {
+			return fmt.Sprintf("{{index .Outputs %q}}", name)
+		}
+		if legacyErr == nil {
+			legacyErr = fmt.Errorf("unknown variable %s in message", s)
+		}
+		return s
+	})
+	if legacyErr != nil {
+		return "", legacyErr
+	}
+
+	tmpl, err := template.New("message").Option("missingkey=error").Funcs(messageFuncs).Parse(message)
+	if err != nil {
+		return "", fmt.Errorf("invalid message template: %w", err)
+	}
+
+	b := strings.Builder{}
+	if err = tmpl.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("invalid message template: %w", err)
+	}
+	return b.String(), nil
+}
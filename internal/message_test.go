@@ -0,0 +1,125 @@
+package internal_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mrvinkel/bump/internal"
+	"github.com/mrvinkel/bump/semver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderMessage(t *testing.T) {
+	newVersion, err := semver.Parse("api-v1.3.0-rc.1")
+	require.NoError(t, err)
+	previousVersion, err := semver.Parse("api-v1.2.4")
+	require.NoError(t, err)
+
+	data, err := internal.NewMessageData(newVersion, previousVersion, map[string]string{"CHECKSUM": "abc123"})
+	require.NoError(t, err)
+	data.Branch = "main"
+	data.Author = "Jane Doe"
+	data.CommitCount = 7
+	data.Date = time.Date(2024, 5, 17, 12, 0, 0, 0, time.UTC)
+
+	type test struct {
+		name     string
+		message  string
+		expected string
+		err      bool
+	}
+
+	tests := []test{
+		{
+			name:     "version",
+			message:  "release {{.Version}}",
+			expected: "release api-v1.3.0-rc.1",
+		},
+		{
+			name:     "all fields",
+			message:  "{{.Component}} {{.Prefix}} {{.Major}}.{{.Minor}}.{{.Patch}} {{.PreRelease}} from {{.PreviousVersion}} on {{.Branch}} by {{.Author}} ({{.CommitCount}} commits)",
+			expected: "api api-v 1.3.0 rc.1 from api-v1.2.4 on main by Jane Doe (7 commits)",
+		},
+		{
+			name:     "helpers",
+			message:  `{{.Date | date "2006-01-02"}} {{.Branch | upper}} {{.Build | default "none"}} {{.Version | trimPrefix .Prefix}}`,
+			expected: "2024-05-17 MAIN none 1.3.0-rc.1",
+		},
+		{
+			name:     "outputs",
+			message:  "sha256: {{.Outputs.CHECKSUM}}",
+			expected: "sha256: abc123",
+		},
+		{
+			name:     "legacy variables",
+			message:  "release ${VERSION} from ${PREVIOUS_VERSION} ${CHECKSUM}",
+			expected: "release api-v1.3.0-rc.1 from api-v1.2.4 abc123",
+		},
+		{
+			name:     "lowercase legacy variables",
+			message:  "release ${version} from ${previous_version}",
+			expected: "release api-v1.3.0-rc.1 from api-v1.2.4",
+		},
+		{
+			name:    "unknown legacy variable",
+			message: "release ${FOO}",
+			err:     true,
+		},
+		{
+			name:    "unknown field",
+			message: "release {{.Foo}}",
+			err:     true,
+		},
+		{
+			name:    "unknown output",
+			message: "release {{.Outputs.FOO}}",
+			err:     true,
+		},
+		{
+			name:    "invalid template",
+			message: "release {{.Version",
+			err:     true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := internal.RenderMessage(tc.message, data)
+			if tc.err {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestMessageDataTooLarge(t *testing.T) {
+	newVersion, err := semver.Parse("v1.99999999999999999999.0")
+	require.NoError(t, err)
+	previousVersion, err := semver.Parse("v1.2.4")
+	require.NoError(t, err)
+
+	_, err = internal.NewMessageData(newVersion, previousVersion, nil)
+	assert.EqualError(t, err, "minor version 99999999999999999999 is too large")
+}
+
+func TestComponent(t *testing.T) {
+	tests := map[string]string{
+		"":                "",
+		"v":               "",
+		"api-":            "api",
+		"api-v":           "api",
+		"tools/cli/v":     "tools/cli",
+		"some-component-": "some-component",
+		"release@":        "release",
+	}
+
+	for prefix, expected := range tests {
+		t.Run(prefix, func(t *testing.T) {
+			assert.Equal(t, expected, internal.Component(prefix))
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package internal
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	SCHEME_NUMERIC         = "numeric"
+	DEFAULT_NUMERIC_FORMAT = "{major}.{minor}.{patch}.{build}"
+
+	LEVEL_BUILD = "build"
+)
+
+var numericSegment = regexp.MustCompile(`\{([a-z][a-z0-9_]*)\}`)
+
+// Numeric is a versioning scheme of N numeric segments in a tag template like
+// release/{major}.{minor}.{patch}.{build}. Segments are named by the template, most
+// significant first, and the names are the bump levels.
+type Numeric struct {
+	format string
+	// names of the segments in format order
+	names []string
+	// literals before, between and after the segments
+	literals []string
+	re       *regexp.Regexp
+}
+
+func NewNumeric(format string) (*Numeric, error) {
+	n := &Numeric{format: format}
+	regex := strings.Builder{}
+	regex.WriteString("^")
+
+	last := 0
+	for _, match := range numericSegment.FindAllStringSubmatchIndex(format, -1) {
+		literal := format[last:match[0]]
+		name := format[match[2]:match[3]]
+		if literal == "" && len(n.names) > 0 {
+			return nil, fmt.Errorf("invalid format %q: segments must be separated, e.g. {%s}.{%s}", format, n.names[len(n.names)-1], name)
+		}
+		if slices.Contains(n.names, name) {
+			return nil, fmt.Errorf("invalid format %q: more than one {%s}", format, name)
+		}
+		n.literals = append(n.literals, literal)
+		n.names = append(n.names, name)
+		regex.WriteString(regexp.QuoteMeta(literal))
+		regex.WriteString(`(0|[1-9]\d*)`)
+		last = match[1]
+	}
+	if len(n.names) == 0 {
+		return nil, fmt.Errorf("invalid format %q: no segments, e.g. {major}", format)
+	}
+	rest := format[last:]
+	if strings.ContainsAny(strings.Join(append(n.literals, rest), ""), "{}") {
+		return nil, fmt.Errorf("invalid format %q: segment names must be lowercase letters, digits and _", format)
+	}
+	n.literals = append(n.literals, rest)
+	regex.WriteString(regexp.QuoteMeta(rest))
+	regex.WriteString("$")
+	n.re = regexp.MustCompile(regex.String())
+	return n, nil
+}
+
+func (n *Numeric) Name() string {
+	return SCHEME_NUMERIC
+}
+
+func (n *Numeric) Format() string {
+	return n.format
+}
+
+// Levels returns the segment names, most significant first
+func (n *Numeric) Levels() []string {
+	return n.names
+}
+
+func (n *Numeric) Parse(tag string) (Versioned, error) {
+	matches := n.re.FindStringSubmatch(tag)
+	if matches == nil {
+		return nil, fmt.Errorf("invalid version format, expected %s", n.format)
+	}
+
+	v := &NumericVersion{scheme: n}
+	for i, s := range matches[1:] {
+		value, err := strconv.Atoi(s)
+		if err != nil {
+			return nil, fmt.Errorf("%s %s is too large", n.names[i], s)
+		}
+		v.Values = append(v.Values, value)
+	}
+	return v, nil
+}
+
+func (n *Numeric) Validate(v Versioned) error {
+	return nil
+}
+
+func (n *Numeric) Initial() Versioned {
+	return &NumericVersion{Values: make([]int, len(n.names)), scheme: n}
+}
+
+// NumericVersion is a version of the numeric scheme. Values holds the value of each
+// segment in format order.
+type NumericVersion struct {
+	Values []int
+	scheme *Numeric
+}
+
+// Segment returns the value of a named segment
+func (v *NumericVersion) Segment(name string) (int, bool) {
+	i := slices.Index(v.scheme.names, name)
+	if i < 0 {
+		return 0, false
+	}
+	return v.Values[i], true
+}
+
+// Prefix returns the literal text of the format before the first segment
+func (v *NumericVersion) Prefix() string {
+	return v.scheme.literals[0]
+}
+
+// Bump increments the named segment and resets the less significant segments to 0
+func (v *NumericVersion) Bump(level string) (*NumericVersion, error) {
+	i := slices.Index(v.scheme.names, level)
+	if i < 0 {
+		return nil, fmt.Errorf("bumping %s is not supported by the format %s, levels are %s", level, v.scheme.format, SliceString(v.scheme.names))
+	}
+
+	next := &NumericVersion{Values: append([]int{}, v.Values...), scheme: v.scheme}
+	next.Values[i]++
+	for j := i + 1; j < len(next.Values); j++ {
+		next.Values[j] = 0
+	}
+	return next, nil
+}
+
+func (v *NumericVersion) String() string {
+	b := strings.Builder{}
+	for i, value := range v.Values {
+		b.WriteString(v.scheme.literals[i])
+		b.WriteString(strconv.Itoa(value))
+	}
+	b.WriteString(v.scheme.literals[len(v.Values)])
+	return b.String()
+}
+
+func (v *NumericVersion) Compare(other fmt.Stringer) int {
+	o, ok := other.(*NumericVersion)
+	if !ok || len(o.Values) != len(v.Values) {
+		return strings.Compare(v.String(), other.String())
+	}
+	for i := range v.Values {
+		if c := compareInt(v.Values[i], o.Values[i]); c != equal {
+			return c
+		}
+	}
+	return equal
+}
@@ -0,0 +1,122 @@
+package internal_test
+
+import (
+	"testing"
+
+	"github.com/mrvinkel/bump/internal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewNumericInvalidFormat(t *testing.T) {
+	for _, format := range []string{
+		"",
+		"v1.2.3",
+		"{major}{minor}",
+		"{major}.{major}",
+		"{Major}.{minor}",
+		"{major}.{minor",
+	} {
+		t.Run(format, func(t *testing.T) {
+			_, err := internal.NewNumeric(format)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestNumericParse(t *testing.T) {
+	type test struct {
+		format   string
+		tag      string
+		expected []int
+		err      bool
+	}
+
+	tests := []test{
+		{format: internal.DEFAULT_NUMERIC_FORMAT, tag: "1.2.3.4", expected: []int{1, 2, 3, 4}},
+		{format: "release/{major}.{minor}.{patch}", tag: "release/1.20.3", expected: []int{1, 20, 3}},
+		{format: "fw-{major}_{build}", tag: "fw-3_1024", expected: []int{3, 1024}},
+		{format: internal.DEFAULT_NUMERIC_FORMAT, tag: "1.2.3", err: true},
+		{format: internal.DEFAULT_NUMERIC_FORMAT, tag: "1.2.3.04", err: true},
+		{format: internal.DEFAULT_NUMERIC_FORMAT, tag: "v1.2.3.4", err: true},
+		{format: "release/{major}.{minor}.{patch}", tag: "1.2.3", err: true},
+		{format: internal.DEFAULT_NUMERIC_FORMAT, tag: "1.2.3.99999999999999999999", err: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.tag, func(t *testing.T) {
+			scheme, err := internal.NewNumeric(tc.format)
+			require.NoError(t, err)
+
+			actual, err := scheme.Parse(tc.tag)
+			if tc.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual.(*internal.NumericVersion).Values)
+			assert.Equal(t, tc.tag, actual.String())
+		})
+	}
+}
+
+func TestNumericBump(t *testing.T) {
+	scheme, err := internal.NewNumeric("release/{major}.{minor}.{patch}.{build}")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"major", "minor", "patch", "build"}, scheme.Levels())
+	assert.Equal(t, "release/0.0.0.0", scheme.Initial().String())
+
+	v, err := scheme.Parse("release/1.2.3.4")
+	require.NoError(t, err)
+	version := v.(*internal.NumericVersion)
+
+	for level, expected := range map[string]string{
+		"build": "release/1.2.3.5",
+		"patch": "release/1.2.4.0",
+		"minor": "release/1.3.0.0",
+		"major": "release/2.0.0.0",
+	} {
+		next, err := version.Bump(level)
+		require.NoError(t, err)
+		assert.Equal(t, expected, next.String())
+		assert.Equal(t, 1, next.Compare(version))
+	}
+	assert.Equal(t, "release/1.2.3.4", version.String())
+
+	_, err = version.Bump("revision")
+	assert.ErrorContains(t, err, "levels are major, minor, patch, build")
+
+	build, ok := version.Segment("build")
+	assert.True(t, ok)
+	assert.Equal(t, 4, build)
+	assert.Equal(t, "release/", version.Prefix())
+}
+
+func TestNumericCompare(t *testing.T) {
+	scheme, err := internal.NewNumeric(internal.DEFAULT_NUMERIC_FORMAT)
+	require.NoError(t, err)
+
+	parse := func(tag string) internal.Versioned {
+		v, err := scheme.Parse(tag)
+		require.NoError(t, err)
+		return v
+	}
+
+	assert.Equal(t, -1, parse("1.2.3.4").Compare(parse("1.2.3.10")))
+	assert.Equal(t, 1, parse("1.10.0.0").Compare(parse("1.9.9.9")))
+	assert.Equal(t, 0, parse("1.2.3.4").Compare(parse("1.2.3.4")))
+}
+
+func TestNumericMessageData(t *testing.T) {
+	scheme, err := internal.NewNumeric("v{major}.{minor}.{patch}.{build}")
+	require.NoError(t, err)
+	v, err := scheme.Parse("v1.2.3.4")
+	require.NoError(t, err)
+
+	data, err := internal.NewMessageData(v, scheme.Initial(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, "v", data.Prefix)
+	assert.Equal(t, 1, data.Major)
+	assert.Equal(t, 2, data.Minor)
+	assert.Equal(t, 3, data.Patch)
+}
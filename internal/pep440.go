@@ -0,0 +1,363 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	SCHEME_PEP440 = "pep440"
+	// pep440 regex from PEP 440 appendix B plus a prefix group
+	pep440 = `(?i)^(?P<prefix>[^0-9]*)` +
+		`(?:(?P<epoch>[0-9]+)!)?` +
+		`(?P<release>[0-9]+(?:\.[0-9]+)*)` +
+		`(?P<pre>[-_\.]?(?P<pre_l>alpha|a|beta|b|preview|pre|c|rc)[-_\.]?(?P<pre_n>[0-9]+)?)?` +
+		`(?P<post>(?:-(?P<post_n1>[0-9]+))|(?:[-_\.]?(?P<post_l>post|rev|r)[-_\.]?(?P<post_n2>[0-9]+)?))?` +
+		`(?P<dev>[-_\.]?(?P<dev_l>dev)[-_\.]?(?P<dev_n>[0-9]+)?)?` +
+		`(?:\+(?P<local>[a-z0-9]+(?:[-_\.][a-z0-9]+)*))?$`
+)
+
+var (
+	pep440Regex     = regexp.MustCompile(pep440)
+	pep440LocalRe   = regexp.MustCompile(`(?i)^[a-z0-9]+(?:[-_\.][a-z0-9]+)*$`)
+	pep440PreLabels = map[string]string{
+		"a": "a", "alpha": "a",
+		"b": "b", "beta": "b",
+		"c": "rc", "rc": "rc", "pre": "rc", "preview": "rc",
+	}
+	pep440PreOrder = map[string]int{"a": 0, "b": 1, "rc": 2}
+)
+
+// PEP440 is the Python versioning scheme, see https://peps.python.org/pep-0440
+type PEP440 struct{}
+
+func (PEP440) Name() string {
+	return SCHEME_PEP440
+}
+
+func (PEP440) Parse(tag string) (Versioned, error) {
+	return ParsePEP440Version(tag)
+}
+
+func (PEP440) Initial() Versioned {
+	return &PEP440Version{Release: []int{0, 0, 0}}
+}
+
+func (PEP440) Validate(v Versioned) error {
+	return nil
+}
+
+// PEP440Version is a PEP 440 version. Nil fields are not set.
+type PEP440Version struct {
+	Prefix   *string
+	Epoch    int
+	Release  []int
+	PreLabel string // a, b or rc, empty if not a pre-release
+	PreNum   int
+	Post     *int
+	Dev      *int
+	Local    *string
+	// tag is the version as parsed, empty if it was not parsed
+	tag string
+}
+
+// ParsePEP440Version parses a version in any form allowed by PEP 440. The version is
+// normalized, e.g. 1.0-alpha.1 is parsed as 1.0a1.
+func ParsePEP440Version(version string) (*PEP440Version, error) {
+	matches := pep440Regex.FindStringSubmatch(version)
+	if matches == nil {
+		return nil, errors.New("invalid version format")
+	}
+	group := func(name string) string {
+		return matches[pep440Regex.SubexpIndex(name)]
+	}
+
+	v := &PEP440Version{tag: version}
+	if prefix := group("prefix"); prefix != "" {
+		v.Prefix = &prefix
+	}
+
+	var err error
+	if group("epoch") != "" {
+		if v.Epoch, err = strconv.Atoi(group("epoch")); err != nil {
+			return nil, err
+		}
+	}
+	for _, part := range strings.Split(group("release"), ".") {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, err
+		}
+		v.Release = append(v.Release, n)
+	}
+
+	if group("pre") != "" {
+		v.PreLabel = pep440PreLabels[strings.ToLower(group("pre_l"))]
+		if n := group("pre_n"); n != "" {
+			if v.PreNum, err = strconv.Atoi(n); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if group("post") != "" {
+		n := group("post_n1") + group("post_n2")
+		post := 0
+		if n != "" {
+			if post, err = strconv.Atoi(n); err != nil {
+				return nil, err
+			}
+		}
+		v.Post = &post
+	}
+
+	if group("dev") != "" {
+		dev := 0
+		if n := group("dev_n"); n != "" {
+			if dev, err = strconv.Atoi(n); err != nil {
+				return nil, err
+			}
+		}
+		v.Dev = &dev
+	}
+
+	if local := group("local"); local != "" {
+		v.Local = new(normalizeLocal(local))
+	}
+	return v, nil
+}
+
+func normalizeLocal(local string) string {
+	return strings.NewReplacer("-", ".", "_", ".").Replace(strings.ToLower(local))
+}
+
+// Tag returns the version as it was parsed, e.g. the name of the tag, or the normalized
+// version if it was not parsed
+func (v *PEP440Version) Tag() string {
+	if v.tag != "" {
+		return v.tag
+	}
+	return v.String()
+}
+
+// String returns the normalized version
+func (v *PEP440Version) String() string {
+	b := strings.Builder{}
+	if v.Prefix != nil {
+		b.WriteString(*v.Prefix)
+	}
+	if v.Epoch != 0 {
+		fmt.Fprintf(&b, "%d!", v.Epoch)
+	}
+	for i, n := range v.Release {
+		if i > 0 {
+			b.WriteString(".")
+		}
+		b.WriteString(strconv.Itoa(n))
+	}
+	if v.PreLabel != "" {
+		fmt.Fprintf(&b, "%s%d", v.PreLabel, v.PreNum)
+	}
+	if v.Post != nil {
+		fmt.Fprintf(&b, ".post%d", *v.Post)
+	}
+	if v.Dev != nil {
+		fmt.Fprintf(&b, ".dev%d", *v.Dev)
+	}
+	if v.Local != nil {
+		fmt.Fprintf(&b, "+%s", *v.Local)
+	}
+	return b.String()
+}
+
+// Compare orders versions as described in PEP 440
+func (v *PEP440Version) Compare(other fmt.Stringer) int {
+	o, ok := other.(*PEP440Version)
+	if !ok {
+		return strings.Compare(v.String(), other.String())
+	}
+
+	if c := compareInt(v.Epoch, o.Epoch); c != equal {
+		return c
+	}
+	if c := compareRelease(v.Release, o.Release); c != equal {
+		return c
+	}
+
+	vPre, vPreNum := v.preKey()
+	oPre, oPreNum := o.preKey()
+	if c := compareInt(vPre, oPre); c != equal {
+		return c
+	}
+	if c := compareInt(vPreNum, oPreNum); c != equal {
+		return c
+	}
+
+	// no post release sorts before any post release
+	if c := compareInt(optionalInt(v.Post, -1), optionalInt(o.Post, -1)); c != equal {
+		return c
+	}
+	// no dev release sorts after any dev release
+	if c := compareInt(optionalInt(v.Dev, math.MaxInt), optionalInt(o.Dev, math.MaxInt)); c != equal {
+		return c
+	}
+	return compareLocal(v.Local, o.Local)
+}
+
+// preKey returns the sort key of the pre-release. A dev release of a final version
+// sorts before any pre-release, and a final version sorts after any pre-release.
+func (v *PEP440Version) preKey() (int, int) {
+	if v.PreLabel != "" {
+		return pep440PreOrder[v.PreLabel], v.PreNum
+	}
+	if v.Post == nil && v.Dev != nil {
+		return -1, 0
+	}
+	return math.MaxInt, 0
+}
+
+func optionalInt(i *int, def int) int {
+	if i == nil {
+		return def
+	}
+	return *i
+}
+
+func compareInt(a, b int) int {
+	if a > b {
+		return greator
+	} else if a < b {
+		return less
+	}
+	return equal
+}
+
+// compareRelease compares release segments, ignoring trailing zeros so 1.0 == 1.0.0
+func compareRelease(a, b []int) int {
+	for i := range max(len(a), len(b)) {
+		var x, y int
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		if c := compareInt(x, y); c != equal {
+			return c
+		}
+	}
+	return equal
+}
+
+// compareLocal compares local versions segment by segment. Numeric segments sort
+// after alphanumeric segments and no local version sorts first.
+func compareLocal(a, b *string) int {
+	if a == nil || b == nil {
+		if a != nil {
+			return greator
+		} else if b != nil {
+			return less
+		}
+		return equal
+	}
+
+	as := strings.Split(*a, ".")
+	bs := strings.Split(*b, ".")
+	for i := range min(len(as), len(bs)) {
+		an, aErr := strconv.Atoi(as[i])
+		bn, bErr := strconv.Atoi(bs[i])
+		switch {
+		case aErr == nil && bErr == nil:
+			if c := compareInt(an, bn); c != equal {
+				return c
+			}
+		case aErr == nil:
+			return greator
+		case bErr == nil:
+			return less
+		default:
+			if c := strings.Compare(as[i], bs[i]); c != equal {
+				return c
+			}
+		}
+	}
+	return compareInt(len(as), len(bs))
+}
+
+func (v *PEP440Version) copy() *PEP440Version {
+	c := *v
+	c.Release = append([]int{}, v.Release...)
+	c.tag = ""
+	return &c
+}
+
+// BumpPEP440 bumps a PEP 440 version by level. Bumping the release segments drops
+// any pre, post, dev and local parts.
+func BumpPEP440(v *PEP440Version, level string) (*PEP440Version, error) {
+	next := v.copy()
+	next.Local = nil
+
+	switch level {
+	case LEVEL_MAJOR, LEVEL_MINOR, LEVEL_PATCH:
+		i := map[string]int{LEVEL_MAJOR: 0, LEVEL_MINOR: 1, LEVEL_PATCH: 2}[level]
+		for len(next.Release) <= i {
+			next.Release = append(next.Release, 0)
+		}
+		next.Release[i]++
+		for j := i + 1; j < len(next.Release); j++ {
+			next.Release[j] = 0
+		}
+		next.PreLabel, next.PreNum, next.Post, next.Dev = "", 0, nil, nil
+	case LEVEL_PRERELEASE:
+		switch {
+		case next.PreLabel != "" && next.Post == nil && next.Dev == nil:
+			// 1.0a1 -> 1.0a2
+			next.PreNum++
+		case next.Dev != nil:
+			// 1.0a1.dev1 -> 1.0a1.dev2
+			next.Dev = new(*next.Dev + 1)
+		default:
+			return nil, errors.New("no pre-release version to bump")
+		}
+	case LEVEL_POST:
+		// 1.0 -> 1.0.post1, 1.0.post1 -> 1.0.post2
+		next.Post = new(optionalInt(next.Post, 0) + 1)
+		next.Dev = nil
+	case LEVEL_DEV:
+		if next.Dev == nil {
+			return nil, errors.New("no dev release to bump")
+		}
+		next.Dev = new(*next.Dev + 1)
+	default:
+		return nil, fmt.Errorf("bumping %s is not supported by the %s scheme", level, SCHEME_PEP440)
+	}
+	return next, nil
+}
+
+// SetPreRelease makes the version a pre-release of the given channel, e.g. 1.0 -> 1.0rc1
+func (v *PEP440Version) SetPreRelease(channel string) error {
+	label, ok := pep440PreLabels[strings.ToLower(channel)]
+	if !ok {
+		return fmt.Errorf("pre-release %s is not supported by the %s scheme", channel, SCHEME_PEP440)
+	}
+	v.PreLabel = label
+	v.PreNum = 1
+	v.Post = nil
+	v.Dev = nil
+	v.tag = ""
+	return nil
+}
+
+// SetLocal sets the local version label, PEP 440's build metadata
+func (v *PEP440Version) SetLocal(local string) error {
+	if !pep440LocalRe.MatchString(local) {
+		return fmt.Errorf("invalid local version %q", local)
+	}
+	v.Local = new(normalizeLocal(local))
+	v.tag = ""
+	return nil
+}
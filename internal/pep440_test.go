@@ -0,0 +1,167 @@
+package internal_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/mrvinkel/bump/internal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePEP440Version(t *testing.T) {
+	type test struct {
+		version  string
+		expected string
+		err      bool
+	}
+
+	tests := []test{
+		{version: "1.2.3", expected: "1.2.3"},
+		{version: "v1.4.0rc1", expected: "v1.4.0rc1"},
+		{version: "1.2.3a1", expected: "1.2.3a1"},
+		{version: "1.2.3rc2", expected: "1.2.3rc2"},
+		{version: "1.0-alpha.1", expected: "1.0a1"},
+		{version: "1.0.BETA2", expected: "1.0b2"},
+		{version: "1.0c1", expected: "1.0rc1"},
+		{version: "1.0pre", expected: "1.0rc0"},
+		{version: "1.0.post1", expected: "1.0.post1"},
+		{version: "1.0-1", expected: "1.0.post1"},
+		{version: "1.0rev", expected: "1.0.post0"},
+		{version: "1.0.dev3", expected: "1.0.dev3"},
+		{version: "1.0a2.post1.dev3", expected: "1.0a2.post1.dev3"},
+		{version: "2!1.0", expected: "2!1.0"},
+		{version: "v1!2.0", expected: "v1!2.0"},
+		{version: "1.0+ubuntu-1", expected: "1.0+ubuntu.1"},
+		{version: "pkg-1.0.dev1", expected: "pkg-1.0.dev1"},
+		{version: "1.0+", err: true},
+		{version: "1.0.beta.x", err: true},
+		{version: "asdf", err: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.version, func(t *testing.T) {
+			actual, err := internal.ParsePEP440Version(tc.version)
+			if tc.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual.String())
+		})
+	}
+}
+
+func TestPEP440Tag(t *testing.T) {
+	v, err := internal.ParsePEP440Version("v1.4.0-rc.1")
+	require.NoError(t, err)
+	assert.Equal(t, "v1.4.0-rc.1", v.Tag())
+	assert.Equal(t, "v1.4.0rc1", v.String())
+
+	// new versions have no tag yet
+	next, err := internal.BumpPEP440(v, internal.LEVEL_PRERELEASE)
+	require.NoError(t, err)
+	assert.Equal(t, "v1.4.0rc2", next.Tag())
+}
+
+func TestPEP440Compare(t *testing.T) {
+	// ordered examples from PEP 440
+	ordered := []string{
+		"1.dev0",
+		"1.0.dev456",
+		"1.0a1",
+		"1.0a2.dev456",
+		"1.0a12.dev456",
+		"1.0a12",
+		"1.0b1.dev456",
+		"1.0b2",
+		"1.0b2.post345.dev456",
+		"1.0b2.post345",
+		"1.0rc1.dev456",
+		"1.0rc1",
+		"1.0",
+		"1.0+abc.5",
+		"1.0+abc.7",
+		"1.0+5",
+		"1.0.post456.dev34",
+		"1.0.post456",
+		"1.0.15",
+		"1.1.dev1",
+		"1!0.1",
+	}
+
+	for i := range ordered {
+		for j := range ordered {
+			expected := 0
+			if i < j {
+				expected = -1
+			} else if i > j {
+				expected = 1
+			}
+
+			name := fmt.Sprintf("%s vs %s", ordered[i], ordered[j])
+			t.Run(name, func(t *testing.T) {
+				v1, err := internal.ParsePEP440Version(ordered[i])
+				require.NoError(t, err)
+				v2, err := internal.ParsePEP440Version(ordered[j])
+				require.NoError(t, err)
+
+				assert.Equal(t, expected, v1.Compare(v2))
+			})
+		}
+	}
+
+	v1, _ := internal.ParsePEP440Version("1.0")
+	v2, _ := internal.ParsePEP440Version("v1.0.0")
+	assert.Equal(t, 0, v1.Compare(v2))
+}
+
+func TestBumpPEP440(t *testing.T) {
+	type test struct {
+		version  string
+		level    string
+		expected string
+		err      bool
+	}
+
+	tests := []test{
+		{version: "1.4.0rc1", level: internal.LEVEL_PATCH, expected: "1.4.1"},
+		{version: "1.4.2.post1", level: internal.LEVEL_MINOR, expected: "1.5.0"},
+		{version: "1.4+local", level: internal.LEVEL_MAJOR, expected: "2.0"},
+		{version: "1.4", level: internal.LEVEL_PATCH, expected: "1.4.1"},
+		{version: "1.4.0rc1", level: internal.LEVEL_PRERELEASE, expected: "1.4.0rc2"},
+		{version: "1.4.0rc1.dev2", level: internal.LEVEL_PRERELEASE, expected: "1.4.0rc1.dev3"},
+		{version: "1.4.0", level: internal.LEVEL_PRERELEASE, err: true},
+		{version: "1.4.0", level: internal.LEVEL_POST, expected: "1.4.0.post1"},
+		{version: "1.4.0.post1.dev1", level: internal.LEVEL_POST, expected: "1.4.0.post2"},
+		{version: "1.4.0.dev3", level: internal.LEVEL_DEV, expected: "1.4.0.dev4"},
+		{version: "1.4.0", level: internal.LEVEL_DEV, err: true},
+		{version: "1.4.0", level: "unknown", err: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.version+" "+tc.level, func(t *testing.T) {
+			v, err := internal.ParsePEP440Version(tc.version)
+			require.NoError(t, err)
+
+			actual, err := internal.BumpPEP440(v, tc.level)
+			if tc.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual.String())
+			assert.Equal(t, tc.version, v.String(), "bump must not modify the previous version")
+		})
+	}
+}
+
+func TestPEP440SetPreRelease(t *testing.T) {
+	v, err := internal.ParsePEP440Version("1.4.0.dev1")
+	require.NoError(t, err)
+
+	require.NoError(t, v.SetPreRelease("rc"))
+	assert.Equal(t, "1.4.0rc1", v.String())
+
+	assert.Error(t, v.SetPreRelease("gamma"))
+}
@@ -0,0 +1,133 @@
+package internal
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+const (
+	OUTPUT_ENV = "BUMP_OUTPUT"
+)
+
+// Run runs the commands with the shell in dir, or the working directory if dir is empty
+func Run(ctx context.Context, dir, shell string, commands []string, out io.Writer, env map[string]string) error {
+	shellCmd := strings.Split(shell, " ")
+	envSlice := make([]string, 0, len(env))
+	for key, value := range env {
+		envSlice = append(envSlice, key+"="+value)
+	}
+	for _, command := range commands {
+		cmdLine := append(shellCmd, command)
+		cmd := exec.CommandContext(ctx, cmdLine[0], cmdLine[1:]...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), envSlice...)
+		cmd.Stdout = out
+		cmd.Stderr = out
+		err := cmd.Run()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RunWithOutput runs the commands like Run, but exports BUMP_OUTPUT pointing to
+// a temporary file the commands can write KEY=VALUE lines to. The parsed values
+// are returned.
+func RunWithOutput(ctx context.Context, dir, shell string, commands []string, out io.Writer, env map[string]string) (map[string]string, error) {
+	file, err := os.CreateTemp("", "bump-output-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(file.Name()) // nolint:errcheck
+	if err = file.Close(); err != nil {
+		return nil, err
+	}
+
+	hookEnv := make(map[string]string, len(env)+1)
+	for key, value := range env {
+		hookEnv[key] = value
+	}
+	hookEnv[OUTPUT_ENV] = file.Name()
+
+	if err = Run(ctx, dir, shell, commands, out, hookEnv); err != nil {
+		return nil, err
+	}
+
+	file, err = os.Open(file.Name())
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close() // nolint:errcheck
+
+	return ReadOutput(file)
+}
+
+// ReadOutput parses hook output in the same format as GitHub Actions' GITHUB_OUTPUT.
+// Each line is either KEY=VALUE or KEY<<DELIMITER followed by a multiline value
+// terminated by a line containing only DELIMITER.
+func ReadOutput(r io.Reader) (map[string]string, error) {
+	output := map[string]string{}
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSuffix(scanner.Text(), "\r")
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+
+		if key, delimiter, ok := strings.Cut(text, "<<"); ok && !strings.Contains(key, "=") {
+			if err := validateOutputKey(key, line); err != nil {
+				return nil, err
+			}
+			start := line
+			value := []string{}
+			closed := false
+			for scanner.Scan() {
+				line++
+				valueLine := strings.TrimSuffix(scanner.Text(), "\r")
+				if valueLine == delimiter {
+					closed = true
+					break
+				}
+				value = append(value, valueLine)
+			}
+			if !closed {
+				return nil, fmt.Errorf("output line %d: missing delimiter %q for %s", start, delimiter, key)
+			}
+			output[key] = strings.Join(value, "\n")
+			continue
+		}
+
+		key, value, ok := strings.Cut(text, "=")
+		if !ok {
+			return nil, fmt.Errorf("output line %d: expected KEY=VALUE, got %q", line, text)
+		}
+		if err := validateOutputKey(key, line); err != nil {
+			return nil, err
+		}
+		output[key] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return output, nil
+}
+
+func validateOutputKey(key string, line int) error {
+	if key == "" {
+		return fmt.Errorf("output line %d: empty key", line)
+	}
+	for _, c := range key {
+		if !(c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9') {
+			return fmt.Errorf("output line %d: invalid key %q", line, key)
+		}
+	}
+	return nil
+}
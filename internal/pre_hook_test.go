@@ -0,0 +1,108 @@
+package internal_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/mrvinkel/bump/internal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunSuccess(t *testing.T) {
+	shell := "sh -c -"
+	commands := []string{"echo hello", "echo world", "echo $FOO"}
+	var writer bytes.Buffer
+	env := map[string]string{"FOO": "bar"}
+
+	err := internal.Run(t.Context(), "", shell, commands, &writer, env)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "hello\nworld\nbar\n", writer.String())
+}
+
+func TestRunFailure(t *testing.T) {
+	shell := "sh -c -"
+	commands := []string{"exit 1"}
+	var writer bytes.Buffer
+	var env map[string]string
+
+	err := internal.Run(t.Context(), "", shell, commands, &writer, env)
+
+	assert.Error(t, err)
+	assert.Empty(t, writer.String())
+}
+
+func TestRunWithOutput(t *testing.T) {
+	shell := "sh -c -"
+	commands := []string{
+		"echo \"CHECKSUM=$FOO\" >> $BUMP_OUTPUT",
+		"printf 'NOTES<<EOF\\nline 1\\nline 2\\nEOF\\n' >> $BUMP_OUTPUT",
+	}
+	var writer bytes.Buffer
+	env := map[string]string{"FOO": "abc123"}
+
+	output, err := internal.RunWithOutput(t.Context(), "", shell, commands, &writer, env)
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"CHECKSUM": "abc123", "NOTES": "line 1\nline 2"}, output)
+}
+
+func TestReadOutput(t *testing.T) {
+	type test struct {
+		name     string
+		input    string
+		expected map[string]string
+		err      bool
+	}
+
+	tests := []test{
+		{
+			name:     "empty",
+			input:    "",
+			expected: map[string]string{},
+		},
+		{
+			name:     "key value",
+			input:    "A=1\nB=x=y\n\nC=\n",
+			expected: map[string]string{"A": "1", "B": "x=y", "C": ""},
+		},
+		{
+			name:     "last value wins",
+			input:    "A=1\nA=2\n",
+			expected: map[string]string{"A": "2"},
+		},
+		{
+			name:     "multiline",
+			input:    "A<<EOF\nfoo\nbar=baz\nEOF\nB=2\n",
+			expected: map[string]string{"A": "foo\nbar=baz", "B": "2"},
+		},
+		{
+			name:  "missing delimiter",
+			input: "A<<EOF\nfoo\n",
+			err:   true,
+		},
+		{
+			name:  "missing value",
+			input: "A\n",
+			err:   true,
+		},
+		{
+			name:  "invalid key",
+			input: "A B=1\n",
+			err:   true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := internal.ReadOutput(strings.NewReader(tc.input))
+			if tc.err {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expected, actual)
+			}
+		})
+	}
+}
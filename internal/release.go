@@ -0,0 +1,375 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+const (
+	// LEVEL_PROMOTE promotes the latest pre-release like bump promote
+	LEVEL_PROMOTE = "promote"
+	// LEVEL_CALVER bumps a calendar version to the current date like bump calver
+	LEVEL_CALVER = "calver"
+
+	// source of the config fields set by the options of Release
+	SOURCE_OPTIONS = "options"
+)
+
+// Options are the settings of a release without the command line, see Release. The
+// fields match the flags of the command line.
+type Options struct {
+	Dir            string
+	Level          string
+	Version        string
+	Channel        string
+	Prefix         string
+	Build          string
+	Ref            string
+	Remote         string
+	DryRun         bool
+	NoVerify       bool
+	NoFetch        bool
+	NoCommit       bool
+	SkipPreHook    bool
+	SkipPostHook   bool
+	SignOff        bool
+	ViaBranch      bool
+	Strict         bool
+	AllowDowngrade bool
+	Getenv         func(string) string
+	Logger         *slog.Logger
+	// Output receives the output of the hooks, nothing if nil
+	Output io.Writer
+}
+
+// Result is what a release tagged, or would tag in a dry run
+type Result struct {
+	Previous      string
+	Version       string
+	Commit        string
+	ReleaseBranch string
+	Outputs       map[string]string
+	DryRun        bool
+}
+
+// Release runs the release flow like the bump command, with the options in place of
+// the flags. The options override the config like flags, and nothing is read from the
+// command line or changed in the package, so releases can run concurrently.
+func Release(ctx context.Context, opts Options) (*Result, error) {
+	if opts.Version != "" && opts.Level != "" {
+		return nil, errors.New("only one of level and version can be set")
+	}
+	schemeName, fn := opts.bumpFunc()
+
+	log := opts.Logger
+	if log == nil {
+		log = slog.New(slog.DiscardHandler)
+	}
+	getenv := opts.Getenv
+	if getenv == nil {
+		getenv = os.Getenv
+	}
+	dir := opts.Dir
+	if dir == "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+		dir = cwd
+	}
+
+	ws, err := openWorkspace(dir, log)
+	if err != nil {
+		return nil, err
+	}
+
+	config, _, err := resolveConfig(ws.root, ws.branch, getenv, opts.layers())
+	if err != nil {
+		return nil, err
+	}
+
+	rc := newReleaseContext(ws, config, opts, log, getenv)
+	return release(ctx, rc, schemeName, fn)
+}
+
+// bumpFunc returns the bump of the level or version, and the scheme it requires
+func (opts Options) bumpFunc() (string, bumpFunc) {
+	switch {
+	case opts.Version != "":
+		return "", setBump(opts.Version)
+	case opts.Level == LEVEL_PROMOTE:
+		return "", promoteBump
+	case opts.Level == LEVEL_CALVER:
+		return SCHEME_CALVER, calverBump
+	case opts.Level == "":
+		return "", levelBump(LEVEL_PATCH)
+	}
+	return "", levelBump(opts.Level)
+}
+
+// layers returns the options that override the config, like flagLayers
+func (opts Options) layers() []configLayer {
+	config := &Config{}
+	if opts.Channel != "" {
+		config.Channel = &opts.Channel
+	}
+	if opts.Prefix != "" {
+		config.Prefix = &opts.Prefix
+	}
+	if opts.Remote != "" {
+		config.Remote = &opts.Remote
+	}
+	if opts.NoVerify {
+		config.Verify = new(false)
+	}
+	if opts.NoFetch {
+		config.Fetch = new(false)
+	}
+	if opts.NoCommit {
+		config.Commit = new(false)
+	}
+	if opts.SignOff {
+		config.SignOff = new(true)
+	}
+	if opts.ViaBranch {
+		config.ViaBranch = new(true)
+	}
+	if opts.Strict {
+		config.Strict = new(true)
+	}
+	return []configLayer{{Source: SOURCE_OPTIONS, Config: config}}
+}
+
+// workspace is the repository a release runs in
+type workspace struct {
+	repo *Repo
+	// root is the top level directory of the repository
+	root string
+	// dir is the directory the release was started in, where hooks run
+	dir    string
+	branch string
+}
+
+func openWorkspace(dir string, log *slog.Logger) (*workspace, error) {
+	repo, err := NewRepo(dir)
+	if err != nil {
+		return nil, err
+	}
+	repo.log = log
+
+	root, err := repo.GetDir()
+	if err != nil {
+		return nil, err
+	}
+
+	branch, err := currentBranch(repo)
+	if err != nil {
+		return nil, err
+	}
+	return &workspace{repo: repo, root: root, dir: dir, branch: branch}, nil
+}
+
+// releaseContext is everything a release reads: the workspace, the resolved config and
+// the settings of the flags or options with the config applied
+type releaseContext struct {
+	*workspace
+	config *Config
+	log    *slog.Logger
+	getenv func(string) string
+	// out receives the output of the hooks
+	out io.Writer
+
+	dryRun         bool
+	verify         bool
+	fetch          bool
+	commit         bool
+	signOff        bool
+	viaBranch      bool
+	strict         bool
+	skipPreHook    bool
+	skipPostHook   bool
+	allowDowngrade bool
+	ref            string
+	remote         string
+	prefix         string
+	build          string
+	channel        string
+}
+
+// newReleaseContext applies the config to the options. The merged config already has
+// the flags or options as its top layers, so its fields are used where set, and the
+// options only fill in the settings the config does not carry. The channel is only the
+// one of the flags or options, as it is a request to promote and set too; level bumps
+// fall back to the channel of the config, see bumpChannel.
+func newReleaseContext(ws *workspace, config *Config, opts Options, log *slog.Logger, getenv func(string) string) *releaseContext {
+	rc := &releaseContext{
+		workspace:      ws,
+		config:         config,
+		log:            log,
+		getenv:         getenv,
+		out:            opts.Output,
+		dryRun:         opts.DryRun,
+		verify:         !opts.NoVerify,
+		fetch:          !opts.NoFetch,
+		commit:         !opts.NoCommit,
+		signOff:        opts.SignOff,
+		viaBranch:      opts.ViaBranch,
+		strict:         opts.Strict,
+		skipPreHook:    opts.SkipPreHook,
+		skipPostHook:   opts.SkipPostHook,
+		allowDowngrade: opts.AllowDowngrade,
+		ref:            opts.Ref,
+		remote:         DEFAULT_REMOTE,
+		prefix:         opts.Prefix,
+		build:          opts.Build,
+		channel:        opts.Channel,
+	}
+	if rc.out == nil {
+		rc.out = io.Discard
+	}
+	if opts.Remote != "" {
+		rc.remote = opts.Remote
+	}
+	if config != nil && config.Remote != nil {
+		rc.remote = *config.Remote
+	}
+	rc.repo.remote = rc.remote
+	rc.repo.log = log
+	if config == nil {
+		return rc
+	}
+
+	bools := map[*bool]*bool{
+		&rc.verify:    config.Verify,
+		&rc.fetch:     config.Fetch,
+		&rc.commit:    config.Commit,
+		&rc.signOff:   config.SignOff,
+		&rc.viaBranch: config.ViaBranch,
+		&rc.strict:    config.Strict,
+	}
+	for field, value := range bools {
+		if value != nil {
+			*field = *value
+		}
+	}
+	if config.Prefix != nil {
+		rc.prefix = *config.Prefix
+	}
+	return rc
+}
+
+// bumpChannel returns the pre-release channel of a level bump: the channel of the
+// flags or options, or else the channel of the config, e.g. set for the branch
+func (rc *releaseContext) bumpChannel() string {
+	if rc.channel == "" && rc.config != nil && rc.config.Channel != nil {
+		return *rc.config.Channel
+	}
+	return rc.channel
+}
+
+// flagOptions reads the options of a release from the flags of a command. Flags the
+// command does not have are left unset.
+func flagOptions(flags *pflag.FlagSet) (Options, error) {
+	opts := Options{}
+	bools := map[string]*bool{
+		"dry-run":         &opts.DryRun,
+		"no-verify":       &opts.NoVerify,
+		"no-fetch":        &opts.NoFetch,
+		"no-commit":       &opts.NoCommit,
+		"skip-pre-hook":   &opts.SkipPreHook,
+		"skip-post-hook":  &opts.SkipPostHook,
+		"sign-off":        &opts.SignOff,
+		"via-branch":      &opts.ViaBranch,
+		"strict":          &opts.Strict,
+		"allow-downgrade": &opts.AllowDowngrade,
+	}
+	for name, field := range bools {
+		if flags.Lookup(name) == nil {
+			continue
+		}
+		value, err := flags.GetBool(name)
+		if err != nil {
+			return opts, err
+		}
+		*field = value
+	}
+	strings := map[string]*string{
+		"ref":     &opts.Ref,
+		"remote":  &opts.Remote,
+		"prefix":  &opts.Prefix,
+		"build":   &opts.Build,
+		"channel": &opts.Channel,
+	}
+	for name, field := range strings {
+		if flags.Lookup(name) == nil {
+			continue
+		}
+		value, err := flags.GetString(name)
+		if err != nil {
+			return opts, err
+		}
+		*field = value
+	}
+
+	// --alpha, --beta and --rc are shorthands for --channel
+	count := 0
+	if opts.Channel != "" {
+		count++
+	}
+	for _, channel := range []string{"alpha", "beta", "rc"} {
+		if flags.Lookup(channel) == nil {
+			continue
+		}
+		set, err := flags.GetBool(channel)
+		if err != nil {
+			return opts, err
+		}
+		if set {
+			opts.Channel = channel
+			count++
+		}
+	}
+	if count > 1 {
+		return opts, errors.New("only one of --channel, --alpha, --beta, --rc can be specified")
+	}
+	return opts, nil
+}
+
+// commandContext builds the release context of a command in the working directory
+// from its flags and the resolved config, logging at the level of the config
+func commandContext(cmd *cobra.Command) (*releaseContext, error) {
+	opts, err := flagOptions(cmd.Flags())
+	if err != nil {
+		return nil, err
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	ws, err := openWorkspace(cwd, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	config, _, err := ResolveConfig(cmd, ws.root, ws.branch, os.Getenv)
+	if err != nil {
+		return nil, err
+	}
+	opts.Output = os.Stderr
+	return newReleaseContext(ws, config, opts, commandLogger(config), os.Getenv), nil
+}
+
+// commandCtx returns the context of a command, which is nil unless it was executed
+func commandCtx(cmd *cobra.Command) context.Context {
+	if ctx := cmd.Context(); ctx != nil {
+		return ctx
+	}
+	return context.Background()
+}
@@ -0,0 +1,574 @@
+package internal_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/mrvinkel/bump/internal"
+	"github.com/mrvinkel/bump/internal/gittest"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const versionHook = `{"preHook": ["echo \"$VERSION\" > VERSION"]}`
+
+func TestRelease(t *testing.T) {
+	type test struct {
+		files map[string]string
+		// setup changes the work tree after the initial release was pushed
+		setup   func(t *testing.T, dir string)
+		opts    internal.Options
+		want    string
+		tagged  bool
+		version string
+		branch  string
+	}
+
+	tests := map[string]test{
+		"patch": {opts: internal.Options{}, want: "v1.0.1", tagged: true},
+		"channel": {
+			opts:   internal.Options{Level: internal.LEVEL_MINOR, Channel: "beta"},
+			want:   "v1.1.0-beta.1",
+			tagged: true,
+		},
+		"config channel": {
+			files:  map[string]string{".bump.json": `{"channel": "rc"}`},
+			want:   "v1.0.1-rc.1",
+			tagged: true,
+		},
+		"prefix": {
+			opts:   internal.Options{Level: internal.LEVEL_MAJOR, Prefix: "release-"},
+			want:   "release-2.0.0",
+			tagged: true,
+		},
+		"set": {
+			opts:   internal.Options{Version: "v1.5.0"},
+			want:   "v1.5.0",
+			tagged: true,
+		},
+		"set with prefix": {
+			opts:   internal.Options{Version: "1.5.0", Prefix: "release-"},
+			want:   "release-1.5.0",
+			tagged: true,
+		},
+		"set with the same prefix": {
+			opts:   internal.Options{Version: "release-1.5.0", Prefix: "release-"},
+			want:   "release-1.5.0",
+			tagged: true,
+		},
+		"set with config channel": {
+			files:  map[string]string{".bump.json": `{"branches": {"master": {"channel": "rc"}}}`},
+			opts:   internal.Options{Version: "v1.5.0"},
+			want:   "v1.5.0",
+			tagged: true,
+		},
+		"promote with config channel": {
+			files:  map[string]string{".bump.json": `{"branches": {"master": {"channel": "beta"}}}`},
+			setup:  tagHead("v1.1.0-beta.1"),
+			opts:   internal.Options{Level: internal.LEVEL_PROMOTE},
+			want:   "v1.1.0-rc.1",
+			tagged: true,
+		},
+		"promote last channel with config channel": {
+			files:  map[string]string{".bump.json": `{"branches": {"master": {"channel": "rc"}}}`},
+			setup:  tagHead("v1.1.0-rc.1"),
+			opts:   internal.Options{Level: internal.LEVEL_PROMOTE},
+			want:   "v1.1.0",
+			tagged: true,
+		},
+		"promote to channel": {
+			files:  map[string]string{".bump.json": `{"branches": {"master": {"channel": "alpha"}}}`},
+			setup:  tagHead("v1.1.0-alpha.1"),
+			opts:   internal.Options{Level: internal.LEVEL_PROMOTE, Channel: "rc"},
+			want:   "v1.1.0-rc.1",
+			tagged: true,
+		},
+		"unreachable pre-release": {
+			setup: func(t *testing.T, dir string) {
+				createTag(t, dir, "v1.1.0-rc.1", gittest.Commit(t, dir, map[string]string{"a.txt": "a"}, "a"))
+			},
+			opts:   internal.Options{Ref: "HEAD~1", Level: internal.LEVEL_MINOR, Channel: "rc"},
+			want:   "v1.1.0-rc.2",
+			tagged: true,
+		},
+		"pre-release after unreachable pre-release": {
+			setup: func(t *testing.T, dir string) {
+				createTag(t, dir, "v1.1.0-rc.1", gittest.Commit(t, dir, map[string]string{"a.txt": "a"}, "a"))
+				createTag(t, dir, "v1.1.0-rc.2", gittest.Commit(t, dir, map[string]string{"b.txt": "b"}, "b"))
+			},
+			opts:   internal.Options{Ref: "HEAD~1", Level: internal.LEVEL_PRERELEASE},
+			want:   "v1.1.0-rc.3",
+			tagged: true,
+		},
+		"dry run": {
+			files: map[string]string{".bump.json": versionHook},
+			opts:  internal.Options{DryRun: true},
+			want:  "v1.0.1",
+		},
+		"pre-hook commit": {
+			files:   map[string]string{".bump.json": versionHook},
+			want:    "v1.0.1",
+			tagged:  true,
+			version: "v1.0.1\n",
+		},
+		"skip pre-hook": {
+			files:  map[string]string{".bump.json": versionHook},
+			opts:   internal.Options{SkipPreHook: true},
+			want:   "v1.0.1",
+			tagged: true,
+		},
+		"no commit": {
+			files:  map[string]string{".bump.json": versionHook},
+			opts:   internal.Options{NoCommit: true},
+			want:   "v1.0.1",
+			tagged: true,
+		},
+		"via branch": {
+			files:  map[string]string{".bump.json": versionHook},
+			opts:   internal.Options{Level: internal.LEVEL_MINOR, ViaBranch: true},
+			want:   "v1.1.0",
+			branch: "release/v1.1.0",
+		},
+		"ref": {
+			setup: func(t *testing.T, dir string) {
+				gittest.Commit(t, dir, map[string]string{"a.txt": "a"}, "a")
+				gittest.Commit(t, dir, map[string]string{"b.txt": "b"}, "b")
+				gittest.Push(t, dir)
+			},
+			opts:   internal.Options{Ref: "HEAD~1"},
+			want:   "v1.0.1",
+			tagged: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			if tc.files == nil {
+				tc.files = map[string]string{}
+			}
+			dir, origin := gittest.NewRepo(t, tc.files)
+			if tc.setup != nil {
+				tc.setup(t, dir)
+			}
+			tc.opts.Dir = dir
+			tc.opts.Getenv = gittest.Getenv(t)
+
+			result, err := internal.Release(t.Context(), tc.opts)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, result.Version)
+			assert.Equal(t, tc.opts.DryRun, result.DryRun)
+			assert.Equal(t, tc.branch, result.ReleaseBranch)
+
+			tag, err := origin.Tag(tc.want)
+			if !tc.tagged {
+				assert.ErrorIs(t, err, git.ErrTagNotFound)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, result.Commit, tag.Hash().String())
+			if tc.version != "" {
+				assert.Equal(t, tc.version, gittest.FileAt(t, origin, tag.Hash(), "VERSION"))
+			}
+		})
+	}
+}
+
+// tagHead returns a setup tagging HEAD and pushing the tag
+func tagHead(tag string) func(t *testing.T, dir string) {
+	return func(t *testing.T, dir string) {
+		repo, err := git.PlainOpen(dir)
+		require.NoError(t, err)
+		head, err := repo.Head()
+		require.NoError(t, err)
+		createTag(t, dir, tag, head.Hash())
+	}
+}
+
+// createTag tags the commit and pushes the tag
+func createTag(t *testing.T, dir, tag string, hash plumbing.Hash) {
+	t.Helper()
+	repo, err := git.PlainOpen(dir)
+	require.NoError(t, err)
+	_, err = repo.CreateTag(tag, hash, nil)
+	require.NoError(t, err)
+	gittest.Push(t, dir)
+}
+
+func TestReleaseExistingTag(t *testing.T) {
+	dir, origin := gittest.NewRepo(t, map[string]string{".bump.json": versionHook})
+	// v1.0.1 is on a commit the ref can not reach
+	createTag(t, dir, "v1.0.1", gittest.Commit(t, dir, map[string]string{"a.txt": "a"}, "a"))
+	head, err := origin.Head()
+	require.NoError(t, err)
+
+	_, err = internal.Release(t.Context(), internal.Options{Dir: dir, Ref: "HEAD~1", Getenv: gittest.Getenv(t)})
+	assert.EqualError(t, err, "tag v1.0.1 already exists")
+
+	tags := []string{}
+	iter, err := origin.Tags()
+	require.NoError(t, err)
+	require.NoError(t, iter.ForEach(func(ref *plumbing.Reference) error {
+		tags = append(tags, ref.Name().Short())
+		return nil
+	}))
+	assert.ElementsMatch(t, []string{"v1.0.0", "v1.0.1"}, tags)
+	after, err := origin.Head()
+	require.NoError(t, err)
+	assert.Equal(t, head.Hash(), after.Hash())
+	_, err = os.Stat(filepath.Join(dir, "VERSION"))
+	assert.ErrorIs(t, err, os.ErrNotExist, "the pre-hook did not run")
+}
+
+func TestReleaseNonNormalizedTag(t *testing.T) {
+	dir, origin := gittest.NewRepo(t, map[string]string{
+		".bump.json": `{"scheme": "pep440", "message": "release {{.Version}} after {{.CommitCount}} commits", "preHook": ["echo \"$VERSION\" > VERSION"]}`,
+	})
+	tagHead("v1.1.0-rc.1")(t, dir)
+	gittest.Commit(t, dir, map[string]string{"a.txt": "a"}, "a")
+	gittest.Push(t, dir)
+
+	// the normalized v1.1.0rc1 exists as v1.1.0-rc.1
+	_, err := internal.Release(t.Context(), internal.Options{Dir: dir, Version: "v1.1.0rc1", AllowDowngrade: true, Getenv: gittest.Getenv(t)})
+	assert.EqualError(t, err, "tag v1.1.0rc1 already exists")
+
+	// the commits are counted from the tag v1.1.0-rc.1
+	_, err = internal.Release(t.Context(), internal.Options{Dir: dir, Level: internal.LEVEL_PRERELEASE, Getenv: gittest.Getenv(t)})
+	require.NoError(t, err)
+	tag, err := origin.Tag("v1.1.0rc2")
+	require.NoError(t, err)
+	commit, err := origin.CommitObject(tag.Hash())
+	require.NoError(t, err)
+	assert.Equal(t, "release v1.1.0rc2 after 1 commits", commit.Message)
+}
+
+func TestReleaseViaBranch(t *testing.T) {
+	dir, origin := gittest.NewRepo(t, map[string]string{".bump.json": versionHook})
+
+	result, err := internal.Release(t.Context(), internal.Options{Dir: dir, ViaBranch: true, Getenv: gittest.Getenv(t)})
+	require.NoError(t, err)
+
+	ref, err := origin.Reference(plumbing.NewBranchReferenceName(result.ReleaseBranch), true)
+	require.NoError(t, err)
+	assert.Equal(t, "v1.0.1\n", gittest.FileAt(t, origin, ref.Hash(), "VERSION"))
+	commit, err := origin.CommitObject(ref.Hash())
+	require.NoError(t, err)
+	assert.Contains(t, commit.Message, internal.RELEASE_TRAILER+": v1.0.1")
+}
+
+func TestReleaseViaBranchPushFails(t *testing.T) {
+	dir, origin := gittest.NewRepo(t, map[string]string{".bump.json": versionHook})
+	repo, err := git.PlainOpen(dir)
+	require.NoError(t, err)
+	originConfig, err := repo.Remote("origin")
+	require.NoError(t, err)
+	require.NoError(t, repo.DeleteRemote("origin"))
+	_, err = repo.CreateRemote(&config.RemoteConfig{Name: "origin", URLs: []string{filepath.Join(t.TempDir(), "missing.git")}})
+	require.NoError(t, err)
+
+	opts := internal.Options{Dir: dir, ViaBranch: true, NoVerify: true, Getenv: gittest.Getenv(t)}
+	_, err = internal.Release(t.Context(), opts)
+	require.Error(t, err)
+
+	_, err = repo.Reference(plumbing.NewBranchReferenceName("release/v1.0.1"), false)
+	assert.ErrorIs(t, err, plumbing.ErrReferenceNotFound, "the branch is deleted")
+	head, err := repo.Head()
+	require.NoError(t, err)
+	assert.Equal(t, plumbing.NewBranchReferenceName("master"), head.Name())
+
+	// a rerun creates the branch again
+	require.NoError(t, repo.DeleteRemote("origin"))
+	_, err = repo.CreateRemote(originConfig.Config())
+	require.NoError(t, err)
+	result, err := internal.Release(t.Context(), opts)
+	require.NoError(t, err)
+	ref, err := origin.Reference(plumbing.NewBranchReferenceName(result.ReleaseBranch), true)
+	require.NoError(t, err)
+	assert.Equal(t, "v1.0.1\n", gittest.FileAt(t, origin, ref.Hash(), "VERSION"))
+}
+
+func TestReleaseViaBranchDetached(t *testing.T) {
+	dir, origin := gittest.NewRepo(t, map[string]string{".bump.json": versionHook})
+	repo, err := git.PlainOpen(dir)
+	require.NoError(t, err)
+	head, err := repo.Head()
+	require.NoError(t, err)
+	w, err := repo.Worktree()
+	require.NoError(t, err)
+	require.NoError(t, w.Checkout(&git.CheckoutOptions{Hash: head.Hash()}))
+
+	result, err := internal.Release(t.Context(), internal.Options{Dir: dir, ViaBranch: true, NoVerify: true, Getenv: gittest.Getenv(t)})
+	require.NoError(t, err)
+
+	ref, err := origin.Reference(plumbing.NewBranchReferenceName(result.ReleaseBranch), true)
+	require.NoError(t, err)
+	assert.Equal(t, "v1.0.1\n", gittest.FileAt(t, origin, ref.Hash(), "VERSION"))
+
+	// HEAD is detached at the same commit again
+	after, err := repo.Head()
+	require.NoError(t, err)
+	assert.Equal(t, plumbing.HEAD, after.Name())
+	assert.Equal(t, head.Hash(), after.Hash())
+	status, err := w.Status()
+	require.NoError(t, err)
+	assert.True(t, status.IsClean(), status.String())
+}
+
+func TestReleaseRemote(t *testing.T) {
+	type test struct {
+		files map[string]string
+		opts  internal.Options
+		env   map[string]string
+	}
+
+	tests := map[string]test{
+		"options": {opts: internal.Options{Remote: "upstream"}},
+		"config":  {files: map[string]string{".bump.json": `{"remote": "upstream"}`}},
+		"env":     {env: map[string]string{"BUMP_REMOTE": "upstream"}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			if tc.files == nil {
+				tc.files = map[string]string{}
+			}
+			dir, origin := gittest.NewRepo(t, tc.files)
+			upstream := gittest.AddRemote(t, dir, "upstream")
+			// origin can not be reached, so nothing may fall back to it
+			repo, err := git.PlainOpen(dir)
+			require.NoError(t, err)
+			require.NoError(t, repo.DeleteRemote("origin"))
+
+			getenv := gittest.Getenv(t)
+			tc.opts.Dir = dir
+			tc.opts.Getenv = func(key string) string {
+				if value, ok := tc.env[key]; ok {
+					return value
+				}
+				return getenv(key)
+			}
+
+			result, err := internal.Release(t.Context(), tc.opts)
+			require.NoError(t, err)
+			assert.Equal(t, "v1.0.1", result.Version)
+
+			tag, err := upstream.Tag("v1.0.1")
+			require.NoError(t, err)
+			assert.Equal(t, result.Commit, tag.Hash().String())
+			_, err = origin.Tag("v1.0.1")
+			assert.ErrorIs(t, err, git.ErrTagNotFound)
+		})
+	}
+}
+
+func TestReleaseRejected(t *testing.T) {
+	type test struct {
+		files map[string]string
+		setup func(t *testing.T, dir string)
+		opts  internal.Options
+		err   string
+	}
+
+	tests := map[string]test{
+		"uncommitted changes": {
+			setup: func(t *testing.T, dir string) {
+				gittest.WriteFiles(t, dir, map[string]string{"readme.md": "changed"})
+			},
+			err: "uncommitted changes",
+		},
+		"unpushed changes": {
+			setup: func(t *testing.T, dir string) {
+				gittest.Commit(t, dir, map[string]string{"a.txt": "a"}, "a")
+			},
+			err: "unpushed changes",
+		},
+		"push not allowed": {
+			files: map[string]string{".bump.json": `{"push": false}`},
+			err:   "pushing from branch master is not allowed by the config, see push",
+		},
+		"existing tag": {
+			opts: internal.Options{Version: "v1.0.0", AllowDowngrade: true},
+			err:  "tag v1.0.0 already exists",
+		},
+		"failing pre-hook": {
+			files: map[string]string{".bump.json": `{"preHook": ["exit 1"]}`},
+			err:   "pre-hook failed",
+		},
+		"pre-release flags with set": {
+			opts: internal.Options{Version: "v1.5.0", Channel: "rc"},
+			err:  "pre-release flags can not be used with set, include the pre-release in the version",
+		},
+		"set with another prefix": {
+			opts: internal.Options{Version: "v1.5.0", Prefix: "release-"},
+			err:  `the prefix "v" of v1.5.0 does not match the prefix "release-"`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			if tc.files == nil {
+				tc.files = map[string]string{}
+			}
+			dir, origin := gittest.NewRepo(t, tc.files)
+			if tc.setup != nil {
+				tc.setup(t, dir)
+			}
+			tc.opts.Dir = dir
+			tc.opts.Getenv = gittest.Getenv(t)
+
+			_, err := internal.Release(t.Context(), tc.opts)
+			assert.ErrorContains(t, err, tc.err)
+
+			tags, err := origin.Tags()
+			require.NoError(t, err)
+			count := 0
+			require.NoError(t, tags.ForEach(func(*plumbing.Reference) error {
+				count++
+				return nil
+			}))
+			assert.Equal(t, 1, count)
+		})
+	}
+
+	t.Run("no verify", func(t *testing.T) {
+		t.Parallel()
+		dir, _ := gittest.NewRepo(t, map[string]string{})
+		gittest.Commit(t, dir, map[string]string{"a.txt": "a"}, "a")
+
+		result, err := internal.Release(t.Context(), internal.Options{Dir: dir, NoVerify: true, DryRun: true, Getenv: gittest.Getenv(t)})
+		require.NoError(t, err)
+		assert.Equal(t, "v1.0.1", result.Version)
+	})
+}
+
+// bumpCommand is a bump command with the release flags of the command line
+func bumpCommand(run func(*cobra.Command, []string) error) *cobra.Command {
+	cmd := &cobra.Command{Use: "bump", RunE: run, SilenceUsage: true, SilenceErrors: true}
+	flags := cmd.Flags()
+	flags.Bool("debug", false, "")
+	flags.Bool("quiet", false, "")
+	flags.Bool("dry-run", false, "")
+	flags.Bool("no-verify", false, "")
+	flags.Bool("no-fetch", false, "")
+	flags.Bool("no-commit", false, "")
+	flags.Bool("skip-pre-hook", false, "")
+	flags.Bool("skip-post-hook", false, "")
+	flags.Bool("sign-off", false, "")
+	flags.Bool("via-branch", false, "")
+	flags.Bool("strict", false, "")
+	flags.String("ref", "", "")
+	flags.String("remote", "", "")
+	flags.String("prefix", "", "")
+	flags.String("build", "", "")
+	flags.String("channel", "", "")
+	flags.Bool("alpha", false, "")
+	flags.Bool("beta", false, "")
+	flags.Bool("rc", false, "")
+	return cmd
+}
+
+func TestBumpCommand(t *testing.T) {
+	dir, origin := gittest.NewRepo(t, map[string]string{".bump.json": `{"channel": "beta", "preHook": ["echo \"$VERSION\" > VERSION"]}`})
+	t.Chdir(dir)
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("BUMP_AUTHOR_NAME", "Release Bot")
+	t.Setenv("BUMP_AUTHOR_EMAIL", "release@example.com")
+
+	// --rc overrides the channel of the config
+	cmd := bumpCommand(internal.BumpLevel(internal.LEVEL_MINOR))
+	cmd.SetArgs([]string{"--rc", "--sign-off"})
+	require.NoError(t, cmd.Execute())
+
+	tag, err := origin.Tag("v1.1.0-rc.1")
+	require.NoError(t, err)
+	assert.Equal(t, "v1.1.0-rc.1\n", gittest.FileAt(t, origin, tag.Hash(), "VERSION"))
+	commit, err := origin.CommitObject(tag.Hash())
+	require.NoError(t, err)
+	assert.Contains(t, commit.Message, "Signed-off-by: Release Bot <release@example.com>")
+
+	cmd = bumpCommand(internal.BumpLevel(internal.LEVEL_MINOR))
+	cmd.SetArgs([]string{"--alpha", "--beta"})
+	assert.EqualError(t, cmd.Execute(), "only one of --channel, --alpha, --beta, --rc can be specified")
+
+	// an empty --channel clears the channel of the config
+	cmd = bumpCommand(internal.BumpPromote)
+	cmd.SetArgs([]string{"--dry-run", "--channel", ""})
+	require.NoError(t, cmd.Execute())
+	_, err = origin.Tag("v1.1.0")
+	assert.ErrorIs(t, err, git.ErrTagNotFound)
+}
+
+func TestBumpCommandLogLevel(t *testing.T) {
+	dir, _ := gittest.NewRepo(t, map[string]string{".bump.json": `{"quiet": true}`})
+	t.Chdir(dir)
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	w := setupLogging(t, internal.LOG_FORMAT_PLAIN, "", false, false)
+
+	// quiet in the config hides the info records of the command only
+	cmd := bumpCommand(internal.BumpLevel(internal.LEVEL_MINOR))
+	cmd.SetArgs([]string{"--dry-run"})
+	require.NoError(t, cmd.Execute())
+	assert.Empty(t, w.String())
+
+	internal.Info("done")
+	assert.Equal(t, "done\n", w.String())
+}
+
+func TestReleaseGoModuleRewrite(t *testing.T) {
+	type test struct {
+		preHook string
+		opts    internal.Options
+		err     string
+		want    string
+	}
+
+	tests := map[string]test{
+		"committed":         {preHook: "true", want: "module example.com/mod/v2\n"},
+		"restored on error": {preHook: "exit 1", err: "pre-hook failed", want: "module example.com/mod\n"},
+		"no commit":         {preHook: "true", opts: internal.Options{NoCommit: true}, err: "can only be rewritten when the release commits HEAD", want: "module example.com/mod\n"},
+		"ref":               {preHook: "true", opts: internal.Options{Ref: "master", NoCommit: true}, err: "can only be rewritten when the release commits HEAD", want: "module example.com/mod\n"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			dir, origin := gittest.NewRepo(t, map[string]string{
+				".bump.json": `{"goModule": "rewrite", "preHook": ["` + tc.preHook + `"]}`,
+				"go.mod":     "module example.com/mod\n",
+				"main.go":    "package main\n\nimport _ \"example.com/mod/pkg\"\n",
+			})
+
+			tc.opts.Dir = dir
+			tc.opts.Level = internal.LEVEL_MAJOR
+			tc.opts.Getenv = gittest.Getenv(t)
+			_, err := internal.Release(t.Context(), tc.opts)
+			content, readErr := os.ReadFile(filepath.Join(dir, "go.mod"))
+			require.NoError(t, readErr)
+			assert.Equal(t, tc.want, string(content))
+			if tc.err != "" {
+				assert.ErrorContains(t, err, tc.err)
+				_, err = origin.Tag("v2.0.0")
+				assert.ErrorIs(t, err, git.ErrTagNotFound)
+				repo, err := git.PlainOpen(dir)
+				require.NoError(t, err)
+				w, err := repo.Worktree()
+				require.NoError(t, err)
+				status, err := w.Status()
+				require.NoError(t, err)
+				assert.True(t, status.IsClean(), status.String())
+				return
+			}
+			require.NoError(t, err)
+			tag, err := origin.Tag("v2.0.0")
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, gittest.FileAt(t, origin, tag.Hash(), "go.mod"))
+		})
+	}
+}
@@ -0,0 +1,523 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// DEFAULT_REMOTE is the remote fetched from and pushed to unless the config sets one
+const DEFAULT_REMOTE = "origin"
+
+type Repo struct {
+	repo *git.Repository
+	log  *slog.Logger
+	// remote is the name of the remote to fetch from and push to
+	remote string
+}
+
+func NewRepo(path string) (*Repo, error) {
+	repo, err := git.PlainOpenWithOptions(path, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return nil, err
+	}
+	return &Repo{repo: repo, log: logger, remote: DEFAULT_REMOTE}, nil
+}
+
+func (r *Repo) GetDir() (string, error) {
+	wt, err := r.repo.Worktree()
+	if err != nil {
+		return "", err
+	}
+	return wt.Filesystem.Root(), nil
+}
+
+// GetTagsReachableFrom returns the tags pointing to the given commit or one of its ancestors
+func (r *Repo) GetTagsReachableFrom(hash plumbing.Hash) ([]string, error) {
+	ancestors := map[plumbing.Hash]bool{}
+	err := r.walk(hash, func(c *object.Commit) error {
+		ancestors[c.Hash] = true
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := r.GetTags()
+	if err != nil {
+		return nil, err
+	}
+	reachable := []string{}
+	for _, tag := range tags {
+		tagHash, err := r.tagCommit(tag)
+		if err != nil {
+			r.log.Debug("skipping tag", "tag", tag, "error", err)
+			continue
+		}
+		if ancestors[tagHash] {
+			reachable = append(reachable, tag)
+		}
+	}
+	return reachable, nil
+}
+
+func (r *Repo) GetTags() ([]string, error) {
+	tagRefs, err := r.repo.Tags()
+	if err != nil {
+		return nil, err
+	}
+
+	tags := []string{}
+	err = tagRefs.ForEach(func(r *plumbing.Reference) error {
+		tags = append(tags, r.Name().Short())
+		return nil
+	})
+	return tags, err
+}
+
+// Branch returns the short name of the checked out branch
+func (r *Repo) Branch() (string, error) {
+	head, err := r.repo.Head()
+	if err != nil {
+		return "", err
+	}
+	return head.Name().Short(), nil
+}
+
+// Identities returns the user, author and committer from the git config
+func (r *Repo) Identities() (GitIdentities, error) {
+	cfg, err := r.repo.ConfigScoped(config.SystemScope)
+	if err != nil {
+		return GitIdentities{}, err
+	}
+	return GitIdentities{
+		User:      Identity{Name: cfg.User.Name, Email: cfg.User.Email},
+		Author:    Identity{Name: cfg.Author.Name, Email: cfg.Author.Email},
+		Committer: Identity{Name: cfg.Committer.Name, Email: cfg.Committer.Email},
+	}, nil
+}
+
+// CommitsSince counts the commits reachable from the given commit but not from the
+// given tag. All commits are counted if the tag does not exist.
+func (r *Repo) CommitsSince(from plumbing.Hash, tag string) (int, error) {
+	seen := map[plumbing.Hash]bool{}
+	tagHash, err := r.tagCommit(tag)
+	if err != nil && err != plumbing.ErrReferenceNotFound {
+		return 0, err
+	}
+	if err == nil {
+		err = r.walk(tagHash, func(c *object.Commit) error {
+			seen[c.Hash] = true
+			return nil
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	count := 0
+	err = r.walk(from, func(c *object.Commit) error {
+		if !seen[c.Hash] {
+			count++
+		}
+		return nil
+	})
+	return count, err
+}
+
+func (r *Repo) walk(from plumbing.Hash, fn func(*object.Commit) error) error {
+	commits, err := r.repo.Log(&git.LogOptions{From: from})
+	if err != nil {
+		return err
+	}
+	defer commits.Close()
+	return commits.ForEach(fn)
+}
+
+// tagCommit returns the hash of the commit a tag points to, following annotated tags
+func (r *Repo) tagCommit(tag string) (plumbing.Hash, error) {
+	ref, err := r.repo.Tag(tag)
+	if err != nil {
+		if err == git.ErrTagNotFound {
+			return plumbing.ZeroHash, plumbing.ErrReferenceNotFound
+		}
+		return plumbing.ZeroHash, err
+	}
+	tagObj, err := r.repo.TagObject(ref.Hash())
+	if err == plumbing.ErrObjectNotFound {
+		// lightweight tag
+		return ref.Hash(), nil
+	}
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	commit, err := tagObj.Commit()
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	return commit.Hash, nil
+}
+
+// ResolveRevision resolves a commit-ish like a hash, branch or tag to a commit
+func (r *Repo) ResolveRevision(rev string) (plumbing.Hash, error) {
+	hash, err := r.repo.ResolveRevision(plumbing.Revision(rev))
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("unable to resolve %s: %w", rev, err)
+	}
+	return *hash, nil
+}
+
+// Head returns the hash of the commit HEAD points to
+func (r *Repo) Head() (plumbing.Hash, error) {
+	head, err := r.repo.Head()
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	return head.Hash(), nil
+}
+
+// CreateTag creates a tag at the given commit. The tag is annotated when a message
+// is given, otherwise it is a lightweight tag.
+func (r *Repo) CreateTag(tag string, hash plumbing.Hash, message string, tagger Identity) error {
+	var opts *git.CreateTagOptions
+	if message != "" {
+		opts = &git.CreateTagOptions{Message: message, Tagger: signature(tagger)}
+	}
+	_, err := r.repo.CreateTag(tag, hash, opts)
+	return err
+}
+
+func (r *Repo) PushTag(ctx context.Context, tag string) error {
+	refSpec := fmt.Sprintf("refs/tags/%s:refs/tags/%s", tag, tag)
+	return r.repo.PushContext(ctx, &git.PushOptions{
+		RemoteName: r.remote,
+		RefSpecs:   []config.RefSpec{config.RefSpec(refSpec)},
+	})
+}
+
+func (r *Repo) TagAndPush(ctx context.Context, tag string, hash plumbing.Hash, message string, tagger Identity) error {
+	err := r.CreateTag(tag, hash, message, tagger)
+	if err != nil {
+		return err
+	}
+	return r.PushTag(ctx, tag)
+}
+
+// CommitAndPush commits all changes and pushes the branch. The hash of the commit is
+// returned.
+func (r *Repo) CommitAndPush(ctx context.Context, message string, author, committer Identity) (plumbing.Hash, error) {
+	w, err := r.repo.Worktree()
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	_, err = w.Add(".")
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	hash, err := w.Commit(message, &git.CommitOptions{
+		Author:    signature(author),
+		Committer: signature(committer),
+	})
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	return hash, r.repo.PushContext(ctx, &git.PushOptions{RemoteName: r.remote})
+}
+
+// CommitToBranchAndPush commits all changes to a new branch created at HEAD, pushes
+// the branch and checks out the original branch again, or the commit of a detached
+// HEAD. The commit is created even if there are no changes. If the commit or push
+// fails the branch is deleted, so it can be created again, and changes that were not
+// committed are kept.
+func (r *Repo) CommitToBranchAndPush(ctx context.Context, branch, message string, author, committer Identity) error {
+	w, err := r.repo.Worktree()
+	if err != nil {
+		return err
+	}
+	head, err := r.repo.Head()
+	if err != nil {
+		return err
+	}
+
+	branchRef := plumbing.NewBranchReferenceName(branch)
+	if _, err = r.repo.Reference(branchRef, false); err == nil {
+		return fmt.Errorf("branch %s already exists", branch)
+	}
+
+	err = w.Checkout(&git.CheckoutOptions{Branch: branchRef, Hash: head.Hash(), Create: true, Keep: true})
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Add(".")
+	if err == nil {
+		_, err = w.Commit(message, &git.CommitOptions{
+			Author:            signature(author),
+			Committer:         signature(committer),
+			AllowEmptyCommits: true,
+		})
+	}
+
+	back := &git.CheckoutOptions{Branch: head.Name(), Keep: err != nil}
+	if !head.Name().IsBranch() {
+		back = &git.CheckoutOptions{Hash: head.Hash(), Keep: err != nil}
+	}
+	if checkoutErr := w.Checkout(back); checkoutErr != nil {
+		return errors.Join(err, checkoutErr)
+	}
+
+	if err == nil {
+		refSpec := fmt.Sprintf("%s:%s", branchRef, branchRef)
+		err = r.repo.PushContext(ctx, &git.PushOptions{
+			RemoteName: r.remote,
+			RefSpecs:   []config.RefSpec{config.RefSpec(refSpec)},
+		})
+	}
+	if err != nil {
+		return errors.Join(err, r.repo.Storer.RemoveReference(branchRef))
+	}
+	return nil
+}
+
+// FindTrailer returns the commits reachable from HEAD with the given trailer key,
+// newest first, mapped to the trailer value.
+func (r *Repo) FindTrailer(key string) ([]TrailerCommit, error) {
+	head, err := r.repo.Head()
+	if err != nil {
+		return nil, err
+	}
+
+	found := []TrailerCommit{}
+	err = r.walk(head.Hash(), func(c *object.Commit) error {
+		for _, value := range Trailers(c.Message, key) {
+			found = append(found, TrailerCommit{Hash: c.Hash, Value: value, Message: c.Message})
+		}
+		return nil
+	})
+	return found, err
+}
+
+// MainlineCommit returns the oldest commit on the first-parent history of HEAD that
+// contains the given commit. That is the commit itself if it is on the main line, or
+// the merge commit of the branch it was merged with.
+func (r *Repo) MainlineCommit(hash plumbing.Hash) (plumbing.Hash, error) {
+	target, err := r.repo.CommitObject(hash)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	head, err := r.repo.Head()
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	c, err := r.repo.CommitObject(head.Hash())
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	found := plumbing.ZeroHash
+	for {
+		if c.Hash == hash {
+			return hash, nil
+		}
+		// older commits on the main line can not contain it once one does not
+		contains, err := target.IsAncestor(c)
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+		if !contains {
+			break
+		}
+		found = c.Hash
+		if c.NumParents() == 0 {
+			break
+		}
+		if c, err = c.Parent(0); err != nil {
+			return plumbing.ZeroHash, err
+		}
+	}
+	if found.IsZero() {
+		return plumbing.ZeroHash, fmt.Errorf("%s is not reachable from HEAD", hash)
+	}
+	return found, nil
+}
+
+type TrailerCommit struct {
+	Hash    plumbing.Hash
+	Value   string
+	Message string
+}
+
+// Trailers returns the values of all trailers with the given key in a commit message
+func Trailers(message, key string) []string {
+	re := regexp.MustCompile(`(?m)^` + regexp.QuoteMeta(key) + `:[ \t]*(.*?)[ \t]*$`)
+	values := []string{}
+	for _, match := range re.FindAllStringSubmatch(message, -1) {
+		values = append(values, match[1])
+	}
+	return values
+}
+
+// HasTag returns true if the tag exists
+func (r *Repo) HasTag(tag string) (bool, error) {
+	_, err := r.repo.Tag(tag)
+	if err == git.ErrTagNotFound {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func signature(i Identity) *object.Signature {
+	return &object.Signature{
+		Name:  i.Name,
+		Email: i.Email,
+		When:  time.Now(),
+	}
+}
+
+func (r *Repo) Fetch(ctx context.Context) error {
+	err := r.repo.FetchContext(ctx, &git.FetchOptions{RemoteName: r.remote})
+	if err != nil && err != git.NoErrAlreadyUpToDate {
+		return err
+	}
+	return nil
+}
+
+func (r *Repo) HasChanges() (bool, string, error) {
+	w, err := r.repo.Worktree()
+	if err != nil {
+		return false, "", err
+	}
+	status, err := w.Status()
+	if err != nil {
+		return false, "", err
+	}
+	changes := strings.TrimSuffix(status.String(), "\n")
+	return !status.IsClean(), changes, nil
+}
+
+func (r *Repo) IsSynced() (bool, error) {
+	head, err := r.repo.Head()
+	if err != nil {
+		return false, err
+	}
+
+	remote, err := r.remoteBranch(head)
+	if err != nil {
+		return false, err
+	}
+	return remote.Hash() == head.Hash(), nil
+}
+
+// IsOnRemoteBranch returns true if the commit is reachable from the remote branch
+// tracked by the checked out branch
+func (r *Repo) IsOnRemoteBranch(hash plumbing.Hash) (bool, error) {
+	head, err := r.repo.Head()
+	if err != nil {
+		return false, err
+	}
+	remote, err := r.remoteBranch(head)
+	if err != nil {
+		return false, err
+	}
+
+	commit, err := r.repo.CommitObject(hash)
+	if err != nil {
+		return false, err
+	}
+	remoteCommit, err := r.repo.CommitObject(remote.Hash())
+	if err != nil {
+		return false, err
+	}
+	return commit.IsAncestor(remoteCommit)
+}
+
+func (r *Repo) remoteBranch(head *plumbing.Reference) (*plumbing.Reference, error) {
+	name := strings.TrimPrefix(head.Name().String(), "refs/heads/")
+
+	refs, err := r.repo.References()
+	if err != nil {
+		return nil, err
+	}
+	// find remote branch
+	var remote *plumbing.Reference
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		if !ref.Name().IsRemote() {
+			return nil
+		}
+		if ref.Name() == plumbing.NewRemoteReferenceName(r.remote, name) {
+			remote = ref
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if remote == nil {
+		return nil, fmt.Errorf("remote branch for %s not found", name)
+	}
+	return remote, nil
+}
+
+// ListRemote lists the references of the remote like git ls-remote, without fetching
+func (r *Repo) ListRemote(ctx context.Context) ([]*plumbing.Reference, error) {
+	remote, err := r.repo.Remote(r.remote)
+	if err != nil {
+		return nil, err
+	}
+	return remote.ListContext(ctx, &git.ListOptions{})
+}
+
+// CheckSynced checks that the branch on the remote, from the references listed by
+// ListRemote, is at HEAD
+func (r *Repo) CheckSynced(refs []*plumbing.Reference, branch string) error {
+	head, err := r.repo.Head()
+	if err != nil {
+		return err
+	}
+
+	name := plumbing.NewBranchReferenceName(branch)
+	var remote *plumbing.Reference
+	for _, ref := range refs {
+		if ref.Name() == name {
+			remote = ref
+		}
+	}
+	if remote == nil {
+		return fmt.Errorf("branch %s does not exist on %s", branch, r.remote)
+	}
+	if remote.Hash() == head.Hash() {
+		return nil
+	}
+
+	remoteCommit, err := r.repo.CommitObject(remote.Hash())
+	if err == plumbing.ErrObjectNotFound {
+		return fmt.Errorf("%s/%s has commits that are not fetched", r.remote, branch)
+	} else if err != nil {
+		return err
+	}
+	commit, err := r.repo.CommitObject(head.Hash())
+	if err != nil {
+		return err
+	}
+	ahead, err := remoteCommit.IsAncestor(commit)
+	if err != nil {
+		return err
+	}
+	if ahead {
+		return errors.New("unpushed changes")
+	}
+	return fmt.Errorf("HEAD is behind or has diverged from %s/%s", r.remote, branch)
+}
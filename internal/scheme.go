@@ -0,0 +1,128 @@
+package internal
+
+import (
+	"fmt"
+
+	"github.com/mrvinkel/bump/semver"
+)
+
+const (
+	SCHEME_SEMVER = "semver"
+	SCHEME_CALVER = "calver"
+
+	LEVEL_MAJOR      = "major"
+	LEVEL_MINOR      = "minor"
+	LEVEL_PATCH      = "patch"
+	LEVEL_PRERELEASE = "prerelease"
+	LEVEL_POST       = "post"
+	LEVEL_DEV        = "dev"
+
+	// compare
+	greator = 1
+	less    = -1
+	equal   = 0
+)
+
+// Versioned is a version of any versioning scheme
+type Versioned interface {
+	String() string
+	// Compare returns 1 if the version is greater than other, -1 if it is less and 0 if
+	// they are equal. Versions of other schemes are compared by their string.
+	Compare(other fmt.Stringer) int
+}
+
+// tagged is a version that keeps the tag it was parsed from, which may differ from
+// its String, e.g. a PEP 440 version is normalized
+type tagged interface {
+	Tag() string
+}
+
+// tagName returns the name of the tag of a parsed version
+func tagName(v Versioned) string {
+	if t, ok := v.(tagged); ok {
+		return t.Tag()
+	}
+	return v.String()
+}
+
+// Scheme parses tags into versions of a versioning scheme
+type Scheme interface {
+	Name() string
+	Parse(tag string) (Versioned, error)
+	// Initial is the previous version when no tags exist
+	Initial() Versioned
+	// Validate checks that a new version is allowed by the scheme
+	Validate(v Versioned) error
+}
+
+// SchemeFor returns the versioning scheme configured in the config, SemVer by default
+func SchemeFor(config *Config) (Scheme, error) {
+	name := SCHEME_SEMVER
+	if config != nil && config.Scheme != nil && *config.Scheme != "" {
+		name = *config.Scheme
+	}
+	format := ""
+	if config != nil && config.Format != nil {
+		format = *config.Format
+	}
+
+	switch name {
+	case SCHEME_SEMVER:
+		return SemVer{Strict: strictMode(config)}, nil
+	case SCHEME_CALVER:
+		if format == "" {
+			format = DEFAULT_CALVER_FORMAT
+		}
+		return NewCalVer(format)
+	case SCHEME_PEP440:
+		return PEP440{}, nil
+	case SCHEME_GO:
+		return GoSemVer{}, nil
+	case SCHEME_NUMERIC:
+		if format == "" {
+			format = DEFAULT_NUMERIC_FORMAT
+		}
+		return NewNumeric(format)
+	}
+	return nil, fmt.Errorf("unknown versioning scheme %q", name)
+}
+
+// SemVer is the semantic versioning scheme, see https://semver.org. In strict mode
+// tags and new versions must follow the spec exactly.
+type SemVer struct {
+	Strict bool
+}
+
+func (SemVer) Name() string {
+	return SCHEME_SEMVER
+}
+
+func (s SemVer) Parse(tag string) (Versioned, error) {
+	if s.Strict {
+		return semver.ParseStrict(tag)
+	}
+	return semver.Parse(tag)
+}
+
+func (SemVer) Initial() Versioned {
+	return semver.New(nil, "0", "0", "0", []string{}, nil)
+}
+
+func (s SemVer) Validate(v Versioned) error {
+	version, ok := v.(*semver.Version)
+	if !ok {
+		return fmt.Errorf("%s is not a semantic version", v)
+	}
+	if s.Strict {
+		return semver.Validate(version)
+	}
+	if version.Build != nil {
+		return semver.ValidateBuild(*version.Build)
+	}
+	return nil
+}
+
+// strictMode returns the strict setting of the config, which includes --strict
+func strictMode(config *Config) bool {
+	return config != nil && config.Strict != nil && *config.Strict
+}
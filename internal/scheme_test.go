@@ -0,0 +1,15 @@
+package internal_test
+
+import (
+	"testing"
+
+	"github.com/mrvinkel/bump/internal"
+	"github.com/mrvinkel/bump/semver"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSemVerValidate(t *testing.T) {
+	assert.NoError(t, internal.SemVer{}.Validate(semver.New(new("app2-"), "1", "0", "0", nil, nil)))
+	assert.Error(t, internal.SemVer{}.Validate(semver.New(nil, "1", "0", "0", nil, new("a b"))))
+	assert.Error(t, internal.SemVer{Strict: true}.Validate(semver.New(new("app2-"), "1", "0", "0", nil, nil)))
+}
@@ -0,0 +1,58 @@
+package internal
+
+import (
+	"fmt"
+
+	"github.com/mrvinkel/bump/semver"
+)
+
+// SetVersion parses an explicit version to release. The prefix and build metadata are
+// used unless the version has its own. The version must be greater than the previous
+// version unless downgrades are allowed.
+func SetVersion(scheme Scheme, version string, previous Versioned, prefix *string, build string, allowDowngrade bool) (Versioned, error) {
+	newVersion, err := scheme.Parse(version)
+	if err != nil {
+		return nil, fmt.Errorf("invalid version %s: %w", version, err)
+	}
+
+	switch v := newVersion.(type) {
+	case *semver.Version:
+		if v.Prefix == nil {
+			v.Prefix = prefix
+		}
+		if v.Build == nil && build != "" {
+			v.Build = &build
+		}
+	case *PEP440Version:
+		if v.Prefix == nil {
+			v.Prefix = prefix
+		}
+		if v.Local == nil && build != "" {
+			if err = v.SetLocal(build); err != nil {
+				return nil, err
+			}
+		}
+	case *CalVersion:
+		if v.Prefix == nil {
+			v.Prefix = prefix
+		}
+	}
+
+	if newVersion.Compare(previous) <= 0 && !allowDowngrade {
+		return nil, fmt.Errorf("%s is not greater than the latest version %s, use --allow-downgrade to release it anyway", newVersion, previous)
+	}
+	return newVersion, nil
+}
+
+// versionPrefix returns the tag prefix of a version
+func versionPrefix(v Versioned) *string {
+	switch v := v.(type) {
+	case *semver.Version:
+		return v.Prefix
+	case *PEP440Version:
+		return v.Prefix
+	case *CalVersion:
+		return v.Prefix
+	}
+	return nil
+}
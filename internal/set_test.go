@@ -0,0 +1,60 @@
+package internal_test
+
+import (
+	"testing"
+
+	"github.com/mrvinkel/bump/internal"
+	"github.com/mrvinkel/bump/semver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetVersion(t *testing.T) {
+	type test struct {
+		name      string
+		version   string
+		previous  string
+		prefix    *string
+		build     string
+		downgrade bool
+		expected  string
+		err       string
+	}
+
+	tests := []test{
+		{name: "greater", version: "2.0.0", previous: "1.3.0", expected: "2.0.0"},
+		{name: "prefix", version: "2.0.0", previous: "v1.3.0", prefix: new("v"), expected: "v2.0.0"},
+		{name: "own prefix", version: "release-2.0.0", previous: "v1.3.0", prefix: new("v"), expected: "release-2.0.0"},
+		{name: "build", version: "2.0.0", previous: "1.3.0", build: "sha.1", expected: "2.0.0+sha.1"},
+		{name: "own build", version: "2.0.0+own", previous: "1.3.0", build: "sha.1", expected: "2.0.0+own"},
+		{name: "pre-release", version: "2.0.0-rc.1", previous: "1.3.0", expected: "2.0.0-rc.1"},
+		{name: "equal", version: "1.3.0", previous: "1.3.0", err: "1.3.0 is not greater than the latest version 1.3.0"},
+		{name: "lower", version: "1.2.9", previous: "1.3.0", err: "use --allow-downgrade"},
+		{name: "downgrade", version: "1.2.9", previous: "1.3.0", downgrade: true, expected: "1.2.9"},
+		{name: "invalid", version: "2.0", previous: "1.3.0", err: "invalid version 2.0"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			previous, err := semver.Parse(tc.previous)
+			require.NoError(t, err)
+
+			actual, err := internal.SetVersion(internal.SemVer{}, tc.version, previous, tc.prefix, tc.build, tc.downgrade)
+			if tc.err != "" {
+				assert.ErrorContains(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual.String())
+		})
+	}
+}
+
+func TestSetVersionPEP440(t *testing.T) {
+	previous, err := internal.ParsePEP440Version("v1.3.0")
+	require.NoError(t, err)
+
+	actual, err := internal.SetVersion(internal.PEP440{}, "2.0.0-rc1", previous, new("v"), "ubuntu", false)
+	require.NoError(t, err)
+	assert.Equal(t, "v2.0.0rc1+ubuntu", actual.String())
+}
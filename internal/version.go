@@ -0,0 +1,20 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+)
+
+func VersionSliceString[V fmt.Stringer](s []V) string {
+	b := strings.Builder{}
+	first := true
+	for _, v := range s {
+		if first {
+			first = false
+		} else {
+			b.WriteString(", ")
+		}
+		b.WriteString(v.String())
+	}
+	return b.String()
+}
@@ -0,0 +1,446 @@
+// Package semver parses, compares and bumps semantic versions, see https://semver.org.
+// Versions can have a prefix like the v of git tags, e.g. v1.2.3-rc.1+build.5.
+package semver
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Version is a semantic version with an optional prefix
+type Version struct {
+	Prefix     *string
+	Major      Number
+	Minor      Number
+	Patch      Number
+	PreRelease []string
+	Build      *string
+}
+
+// Number is a major, minor or patch version of any size, in decimal without leading
+// zeros. The empty string is 0.
+type Number string
+
+// String returns the number, 0 if it is empty
+func (n Number) String() string {
+	if n == "" {
+		return "0"
+	}
+	return string(n)
+}
+
+// Compare compares numbers numerically. Without leading zeros the longer number is the
+// greater one, and numbers of the same length compare like strings.
+func (n Number) Compare(other Number) int {
+	a, b := n.String(), other.String()
+	if c := cmp.Compare(len(a), len(b)); c != equal {
+		return c
+	}
+	return strings.Compare(a, b)
+}
+
+// Next returns the number plus one
+func (n Number) Next() Number {
+	digits := []byte(n.String())
+	for i := len(digits) - 1; i >= 0; i-- {
+		if digits[i] < '9' {
+			digits[i]++
+			return Number(digits)
+		}
+		digits[i] = '0'
+	}
+	return Number("1" + string(digits))
+}
+
+// Int returns the number as an int, or an error if it is too large
+func (n Number) Int() (int, error) {
+	i, err := strconv.Atoi(n.String())
+	if err != nil {
+		return 0, fmt.Errorf("%s is too large", n)
+	}
+	return i, nil
+}
+
+const (
+	// semver regex from semver.org plus a prefix group
+	pattern = `^(?P<prefix>0|[^0-9]*)(?P<major>0|[1-9]\d*)\.(?P<minor>0|[1-9]\d*)\.(?P<patch>0|[1-9]\d*)(?:-(?P<prerelease>(?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*)(?:\.(?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*))*))?(?:\+(?P<buildmetadata>[0-9a-zA-Z-]+(?:\.[0-9a-zA-Z-]+)*))?$`
+	// match groups
+	prefix        = 1
+	major         = 2
+	minor         = 3
+	patch         = 4
+	prerelease    = 5
+	buildmetadata = 6
+	// compare
+	greator = 1
+	less    = -1
+	equal   = 0
+)
+
+// New returns a version. prefix and build are optional.
+func New(prefix *string, major, minor, patch Number, preRelease []string, build *string) *Version {
+	return &Version{
+		Prefix:     prefix,
+		Major:      major,
+		Minor:      minor,
+		Patch:      patch,
+		PreRelease: preRelease,
+		Build:      build,
+	}
+}
+
+// Parse parses a version like v1.2.3-rc.1+build.5. Anything not starting with a digit
+// is the prefix.
+func Parse(version string) (*Version, error) {
+	re := regexp.MustCompile(pattern)
+	matches := re.FindStringSubmatch(version)
+	if matches == nil {
+		return nil, errors.New("invalid version format")
+	}
+
+	prefixStr := matches[prefix]
+	var prefix *string
+	if prefixStr != "" {
+		prefix = &prefixStr
+	}
+
+	major, err := parseVersionNumber("major", matches[major])
+	if err != nil {
+		return nil, err
+	}
+
+	minor, err := parseVersionNumber("minor", matches[minor])
+	if err != nil {
+		return nil, err
+	}
+
+	patch, err := parseVersionNumber("patch", matches[patch])
+	if err != nil {
+		return nil, err
+	}
+
+	var preRelease []string
+	if matches[prerelease] != "" {
+		preRelease = strings.Split(matches[prerelease], ".")
+	}
+
+	buildStr := matches[buildmetadata]
+	var build *string
+	if buildStr != "" {
+		build = &buildStr
+	}
+
+	return &Version{
+		Prefix:     prefix,
+		Major:      major,
+		Minor:      minor,
+		Patch:      patch,
+		PreRelease: preRelease,
+		Build:      build,
+	}, nil
+}
+
+// Alpha makes the version the first alpha pre-release, e.g. 1.2.3-alpha.1
+func (v *Version) Alpha() {
+	v.SetChannel("alpha", 1)
+}
+
+// Beta makes the version the first beta pre-release
+func (v *Version) Beta() {
+	v.SetChannel("beta", 1)
+}
+
+// RC makes the version the first release candidate
+func (v *Version) RC() {
+	v.SetChannel("rc", 1)
+}
+
+// SetChannel makes the version a pre-release on a channel, e.g. 1.2.3-beta.2
+func (v *Version) SetChannel(channel string, number int) {
+	v.PreRelease = []string{channel, strconv.Itoa(number)}
+}
+
+// Channel returns the channel and number of a pre-release like 1.2.3-beta.2. A
+// pre-release without a number, like 1.2.3-beta, is number 0.
+func (v *Version) Channel() (string, int, bool) {
+	switch len(v.PreRelease) {
+	case 1:
+		if isNumeric(v.PreRelease[0]) {
+			return "", 0, false
+		}
+		return v.PreRelease[0], 0, true
+	case 2:
+		if isNumeric(v.PreRelease[0]) {
+			return "", 0, false
+		}
+		n, err := strconv.Atoi(v.PreRelease[1])
+		if err != nil {
+			return "", 0, false
+		}
+		return v.PreRelease[0], n, true
+	}
+	return "", 0, false
+}
+
+// BumpPatch returns the next patch version, dropping any pre-release
+func BumpPatch(v *Version) *Version {
+	return New(v.Prefix, v.Major, v.Minor, v.Patch.Next(), []string{}, v.Build)
+}
+
+// BumpMinor returns the next minor version, dropping any pre-release
+func BumpMinor(v *Version) *Version {
+	return New(v.Prefix, v.Major, v.Minor.Next(), "0", []string{}, v.Build)
+}
+
+// BumpMajor returns the next major version, dropping any pre-release
+func BumpMajor(v *Version) *Version {
+	return New(v.Prefix, v.Major.Next(), "0", "0", []string{}, v.Build)
+}
+
+// BumpPreRelease increments the last numeric identifier of the pre-release, or appends
+// one, e.g. 1.2.3-alpha.1 -> 1.2.3-alpha.2 and 1.2.3-alpha -> 1.2.3-alpha.1
+func BumpPreRelease(v *Version) (*Version, error) {
+	if len(v.PreRelease) == 0 {
+		return nil, errors.New("no pre-release version to bump")
+	}
+
+	preRelease := make([]string, len(v.PreRelease))
+	copy(preRelease, v.PreRelease)
+
+	if len(preRelease) == 1 {
+		if num, ok := parseNumeric(preRelease[0]); ok {
+			preReleaseNum := num.Add(num, big.NewInt(1)).String()
+			// 1.2.3-1 -> 1.2.3-2
+			return New(v.Prefix, v.Major, v.Minor, v.Patch, []string{preReleaseNum}, v.Build), nil
+		} else {
+			// 1.2.3-alpha -> 1.2.3-alpha.1
+			return New(v.Prefix, v.Major, v.Minor, v.Patch, []string{preRelease[0], "1"}, v.Build), nil
+		}
+	}
+
+	last := len(preRelease) - 1
+	if num, ok := parseNumeric(preRelease[last]); ok {
+		preReleaseNum := num.Add(num, big.NewInt(1)).String()
+		// 1.2.3-alpha.1 -> 1.2.3-alpha.2
+		return New(v.Prefix, v.Major, v.Minor, v.Patch, append(preRelease[:last], preReleaseNum), v.Build), nil
+	} else {
+		// 1.2.3-alpha.beta -> 1.2.3-alpha.beta.1
+		return New(v.Prefix, v.Major, v.Minor, v.Patch, append(preRelease, "1"), v.Build), nil
+	}
+}
+
+// Compare compares versions by SemVer precedence, see https://semver.org/#spec-item-11.
+// The prefix and build metadata are ignored.
+func Compare(v1, v2 Version) int {
+	if c := v1.Major.Compare(v2.Major); c != equal {
+		return c
+	}
+	if c := v1.Minor.Compare(v2.Minor); c != equal {
+		return c
+	}
+	if c := v1.Patch.Compare(v2.Patch); c != equal {
+		return c
+	}
+
+	// a pre-release version has lower precedence than the release
+	v1Len := len(v1.PreRelease)
+	v2Len := len(v2.PreRelease)
+	if v1Len == 0 && v2Len > 0 {
+		return greator
+	} else if v1Len > 0 && v2Len == 0 {
+		return less
+	}
+
+	for i := range min(v1Len, v2Len) {
+		if c := compareIdentifier(v1.PreRelease[i], v2.PreRelease[i]); c != equal {
+			return c
+		}
+	}
+
+	// a larger set of pre-release identifiers has higher precedence if all the
+	// preceding identifiers are equal
+	return cmp.Compare(v1Len, v2Len)
+}
+
+// compareIdentifier compares pre-release identifiers. Numeric identifiers are compared
+// numerically and have lower precedence than alphanumeric identifiers, which are
+// compared in ASCII order.
+func compareIdentifier(a, b string) int {
+	aNum, aOk := parseNumeric(a)
+	bNum, bOk := parseNumeric(b)
+
+	switch {
+	case aOk && bOk:
+		return aNum.Cmp(bNum)
+	case aOk:
+		return less
+	case bOk:
+		return greator
+	}
+	return strings.Compare(a, b)
+}
+
+// Compare compares v to other by SemVer precedence like Compare. Other kinds of
+// versions are compared by their string.
+func (v *Version) Compare(other fmt.Stringer) int {
+	o, ok := other.(*Version)
+	if !ok {
+		return strings.Compare(v.String(), other.String())
+	}
+	return Compare(*v, *o)
+}
+
+// Less reports whether v has lower precedence than other
+func (v *Version) Less(other *Version) bool {
+	return Compare(*v, *other) == less
+}
+
+// Versions sorts versions by precedence, lowest first
+type Versions []*Version
+
+func (v Versions) Len() int           { return len(v) }
+func (v Versions) Less(i, j int) bool { return v[i].Less(v[j]) }
+func (v Versions) Swap(i, j int)      { v[i], v[j] = v[j], v[i] }
+
+// parseNumeric parses a numeric identifier of any size
+func parseNumeric(s string) (*big.Int, bool) {
+	if !isNumeric(s) {
+		return nil, false
+	}
+	return new(big.Int).SetString(s, 10)
+}
+
+func isNumeric(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// parseVersionNumber parses a major, minor or patch version of any size
+func parseVersionNumber(name, s string) (Number, error) {
+	if !isNumeric(s) {
+		return "", fmt.Errorf("%s version %q is not a number", name, s)
+	}
+	if len(s) > 1 && s[0] == '0' {
+		return "", fmt.Errorf("%s version %q has a leading zero", name, s)
+	}
+	return Number(s), nil
+}
+
+// String returns the version with its prefix, pre-release and build metadata
+func (v *Version) String() string {
+	version := fmt.Sprintf("%s.%s.%s", v.Major, v.Minor, v.Patch)
+	if v.Prefix != nil {
+		version = fmt.Sprintf("%s%s", *v.Prefix, version)
+	}
+	if len(v.PreRelease) > 0 {
+		version = fmt.Sprintf("%s-%s", version, strings.Join(v.PreRelease, "."))
+	}
+	if v.Build != nil {
+		version = fmt.Sprintf("%s+%s", version, *v.Build)
+	}
+	return version
+}
+
+// ErrNoVersion is returned by ParseStrict for tags that do not look like a
+// version at all, as opposed to versions that are invalid
+var ErrNoVersion = errors.New("not a version")
+
+var looseSemver = regexp.MustCompile(`^[^0-9]*[0-9]+\.[0-9]+\.[0-9]+`)
+
+// ParseStrict parses a version like Parse, but rejects anything not allowed by the
+// SemVer spec with an error describing what is wrong. The prefix can not contain digits.
+func ParseStrict(version string) (*Version, error) {
+	if !looseSemver.MatchString(version) {
+		return nil, fmt.Errorf("%q: %w", version, ErrNoVersion)
+	}
+
+	v := &Version{}
+	rest := version
+	if i := strings.IndexAny(rest, "0123456789"); i > 0 {
+		v.Prefix = new(rest[:i])
+		rest = rest[i:]
+	}
+
+	if core, build, ok := strings.Cut(rest, "+"); ok {
+		v.Build = &build
+		rest = core
+	}
+	if core, preRelease, ok := strings.Cut(rest, "-"); ok {
+		v.PreRelease = strings.Split(preRelease, ".")
+		rest = core
+	}
+
+	numbers := strings.Split(rest, ".")
+	if len(numbers) != 3 {
+		return nil, fmt.Errorf("%q must be MAJOR.MINOR.PATCH", rest)
+	}
+	var err error
+	if v.Major, err = parseVersionNumber("major", numbers[0]); err != nil {
+		return nil, err
+	}
+	if v.Minor, err = parseVersionNumber("minor", numbers[1]); err != nil {
+		return nil, err
+	}
+	if v.Patch, err = parseVersionNumber("patch", numbers[2]); err != nil {
+		return nil, err
+	}
+
+	if err = Validate(v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// Validate checks the prefix, pre-release and build metadata of a version
+// against the SemVer spec
+func Validate(v *Version) error {
+	if v.Prefix != nil && strings.ContainsAny(*v.Prefix, "0123456789") {
+		return fmt.Errorf("prefix %q can not contain digits", *v.Prefix)
+	}
+	for _, id := range v.PreRelease {
+		if err := validateIdentifier("pre-release", id); err != nil {
+			return err
+		}
+		if len(id) > 1 && id[0] == '0' && isNumeric(id) {
+			return fmt.Errorf("pre-release identifier %q has a leading zero", id)
+		}
+	}
+	if v.Build != nil {
+		return ValidateBuild(*v.Build)
+	}
+	return nil
+}
+
+// ValidateBuild checks build metadata against the SemVer spec
+func ValidateBuild(build string) error {
+	for _, id := range strings.Split(build, ".") {
+		if err := validateIdentifier("build metadata", id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateIdentifier(kind, id string) error {
+	if id == "" {
+		return fmt.Errorf("%s identifiers can not be empty", kind)
+	}
+	for _, c := range id {
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '-') {
+			return fmt.Errorf("%s identifier %q contains %q, only [0-9A-Za-z-] are allowed", kind, id, c)
+		}
+	}
+	return nil
+}
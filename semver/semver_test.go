@@ -0,0 +1,808 @@
+package semver_test
+
+import (
+	"fmt"
+	"math/big"
+	"math/rand"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/quick"
+
+	"github.com/mrvinkel/bump/semver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	type test struct {
+		name     string
+		version  string
+		expected *semver.Version
+		err      bool
+	}
+
+	tests := []test{
+		{
+			name:    "valid version",
+			version: "1.2.3",
+			expected: &semver.Version{
+				Major: "1",
+				Minor: "2",
+				Patch: "3",
+			},
+		},
+		{
+			name:    "valid version prefix",
+			version: "v1.2.3",
+			expected: &semver.Version{
+				Prefix: new("v"),
+				Major:  "1",
+				Minor:  "2",
+				Patch:  "3",
+			},
+		},
+		{
+			name:    "valid version component prefix",
+			version: "some-component-1.2.3",
+			expected: &semver.Version{
+				Prefix: new("some-component-"),
+				Major:  "1",
+				Minor:  "2",
+				Patch:  "3",
+			},
+		},
+		{
+			name:    "valid version pre release",
+			version: "1.2.3-alpha.1",
+			expected: &semver.Version{
+				Major:      "1",
+				Minor:      "2",
+				Patch:      "3",
+				PreRelease: []string{"alpha", "1"},
+			},
+		},
+		{
+			name:    "valid version build metadata",
+			version: "1.2.3+build.123",
+			expected: &semver.Version{
+				Major: "1",
+				Minor: "2",
+				Patch: "3",
+				Build: new("build.123"),
+			},
+		},
+		{
+			name:    "valid version all fields",
+			version: "v1.2.3-beta.2+build.123",
+			expected: &semver.Version{
+				Prefix:     new("v"),
+				Major:      "1",
+				Minor:      "2",
+				Patch:      "3",
+				PreRelease: []string{"beta", "2"},
+				Build:      new("build.123"),
+			},
+		},
+		{
+			name:    "valid version double digit",
+			version: "56.43.32",
+			expected: &semver.Version{
+				Major: "56",
+				Minor: "43",
+				Patch: "32",
+			},
+		},
+		{
+			name:    "valid version with prefix",
+			version: "v1.2.3",
+			expected: &semver.Version{
+				Prefix: new("v"),
+				Major:  "1",
+				Minor:  "2",
+				Patch:  "3",
+			},
+		},
+		{
+			name:    "valid version with prefix special character",
+			version: "abc!\"#¤%&/()=?-_,.'¨^1.2.3",
+			expected: &semver.Version{
+				Prefix: new("abc!\"#¤%&/()=?-_,.'¨^"),
+				Major:  "1",
+				Minor:  "2",
+				Patch:  "3",
+			},
+		},
+		{
+			name:    "invalid version",
+			version: "asdf",
+			err:     true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := semver.Parse(tc.version)
+			if tc.err {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tc.expected.String(), actual.String())
+			}
+		})
+	}
+}
+
+func TestCompare(t *testing.T) {
+	greater := 1
+	less := -1
+	equal := 0
+	type test struct {
+		version1 string
+		version2 string
+		expected int
+	}
+
+	tests := []test{
+		{
+			version1: "0.0.0",
+			version2: "0.0.0",
+			expected: equal,
+		},
+		{
+			version1: "0.0.1",
+			version2: "0.0.0",
+			expected: greater,
+		},
+		{
+			version1: "0.1.0",
+			version2: "0.0.1",
+			expected: greater,
+		},
+		{
+			version1: "1.0.0",
+			version2: "0.1.1",
+			expected: greater,
+		},
+		{
+			version1: "1.2.4",
+			version2: "1.2.3",
+			expected: greater,
+		},
+		{
+			// core numbers beyond math.MaxInt64 (9223372036854775807)
+			version1: "9223372036854775808.0.0",
+			version2: "9223372036854775807.0.0",
+			expected: greater,
+		},
+		{
+			version1: "1.99999999999999999999.0",
+			version2: "1.100000000000000000000.0",
+			expected: less,
+		},
+		{
+			version1: "1.0.18446744073709551616",
+			version2: "1.0.18446744073709551616",
+			expected: equal,
+		},
+		{
+			version1: "1.0.18446744073709551617",
+			version2: "1.0.18446744073709551616",
+			expected: greater,
+		},
+		{
+			version1: "1.2.3",
+			version2: "1.2.4",
+			expected: less,
+		},
+		{
+			version1: "1.2.3",
+			version2: "1.2.3-alpha",
+			expected: greater,
+		},
+		{
+			version1: "1.2.3-alpha",
+			version2: "1.2.3",
+			expected: less,
+		},
+		{
+			version1: "1.2.3-alpha.1",
+			version2: "1.2.3-alpha.1",
+			expected: equal,
+		},
+		{
+			version1: "1.2.3-alpha.1",
+			version2: "1.2.3-alpha.1+build",
+			expected: equal,
+		},
+		{
+			version1: "1.2.3-alpha.1+build",
+			version2: "1.2.3-alpha.1",
+			expected: equal,
+		},
+		{
+			version1: "1.2.3-alpha.1+build",
+			version2: "1.2.3-alpha.1+build.1",
+			expected: equal,
+		},
+		{
+			version1: "1.2.3-alpha.1+build.1",
+			version2: "1.2.3-alpha.1+build",
+			expected: equal,
+		},
+		{
+			version1: "1.2.3-alpha.1",
+			version2: "1.2.3-alpha.2",
+			expected: less,
+		},
+		{
+			version1: "1.2.3-alpha.2",
+			version2: "1.2.3-alpha.1",
+			expected: greater,
+		},
+		{
+			version1: "1.2.3-alpha.1",
+			version2: "1.2.3-beta.1",
+			expected: less,
+		},
+		{
+			version1: "1.2.3-beta.1",
+			version2: "1.2.3-alpha.1",
+			expected: greater,
+		},
+		{
+			version1: "1.2.3+build",
+			version2: "1.2.3",
+			expected: equal,
+		},
+		{
+			version1: "1.0.0-alpha",
+			version2: "1.0.0-alpha.1",
+			expected: less,
+		},
+		{
+			version1: "1.0.0-alpha.1",
+			version2: "1.0.0-alpha.beta",
+			expected: less,
+		},
+		{
+			version1: "1.0.0-alpha.beta",
+			version2: "1.0.0-beta",
+			expected: less,
+		},
+		{
+			version1: "1.0.0-beta",
+			version2: "1.0.0-beta.2",
+			expected: less,
+		},
+		{
+			version1: "1.0.0-beta.2",
+			version2: "1.0.0-beta.11",
+			expected: less,
+		},
+		{
+			version1: "1.0.0-beta.11",
+			version2: "1.0.0-rc.1",
+			expected: less,
+		},
+		{
+			version1: "1.0.0-rc.1",
+			version2: "1.0.0",
+			expected: less,
+		},
+		{
+			version1: "1.0.0-rc.99999999999999999999",
+			version2: "1.0.0-rc.100000000000000000000",
+			expected: less,
+		},
+		{
+			version1: "1.0.0-rc.99999999999999999999",
+			version2: "1.0.0-rc.alpha",
+			expected: less,
+		},
+	}
+
+	for i, tc := range tests {
+		expectedStr := ""
+		switch tc.expected {
+		case greater:
+			expectedStr = "greater than"
+		case less:
+			expectedStr = "less than"
+		case equal:
+			expectedStr = "equal ot"
+		}
+
+		name := fmt.Sprintf("%d: %s is %s %s", i, tc.version1, expectedStr, tc.version2)
+		t.Run(name, func(t *testing.T) {
+			v1, err := semver.Parse(tc.version1)
+			require.NoError(t, err)
+			v2, err := semver.Parse(tc.version2)
+			require.NoError(t, err)
+
+			actual := semver.Compare(*v1, *v2)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+// specOrder is the precedence examples of https://semver.org/#spec-item-11, lowest first
+var specOrder = []string{
+	"1.0.0-alpha",
+	"1.0.0-alpha.1",
+	"1.0.0-alpha.1.2",
+	"1.0.0-alpha.2",
+	"1.0.0-alpha.beta",
+	"1.0.0-beta",
+	"1.0.0-beta.2",
+	"1.0.0-beta.11",
+	"1.0.0-rc.1",
+	"1.0.0",
+	"1.0.1-0",
+	"1.0.1-1",
+	"1.0.1-99999999999999999999",
+	"1.0.1-a",
+	"1.0.1",
+	"2.0.0",
+	"2.1.0",
+	"2.1.1",
+	"9223372036854775807.0.0",
+	"9223372036854775808.0.0",
+	"100000000000000000000.0.0",
+}
+
+func TestCompareSpecOrder(t *testing.T) {
+	for i, a := range specOrder {
+		for j, b := range specOrder {
+			expected := 0
+			if i < j {
+				expected = -1
+			} else if i > j {
+				expected = 1
+			}
+
+			t.Run(a+" "+b, func(t *testing.T) {
+				v1, err := semver.Parse(a)
+				require.NoError(t, err)
+				v2, err := semver.Parse(b)
+				require.NoError(t, err)
+
+				assert.Equal(t, expected, semver.Compare(*v1, *v2))
+				assert.Equal(t, expected == -1, v1.Less(v2))
+			})
+		}
+	}
+}
+
+func TestSortVersions(t *testing.T) {
+	versions := semver.Versions{}
+	for _, v := range specOrder {
+		version, err := semver.Parse(v)
+		require.NoError(t, err)
+		versions = append(versions, version)
+	}
+	rand.New(rand.NewSource(1)).Shuffle(len(versions), versions.Swap)
+
+	sort.Sort(versions)
+
+	sorted := []string{}
+	for _, v := range versions {
+		sorted = append(sorted, v.String())
+	}
+	assert.Equal(t, specOrder, sorted)
+}
+
+func TestVersionString(t *testing.T) {
+	type test struct {
+		version  *semver.Version
+		expected string
+	}
+	tests := []test{
+		{
+			version: &semver.Version{
+				Major: "1",
+				Minor: "2",
+				Patch: "3",
+			},
+			expected: "1.2.3",
+		},
+		{
+			version: &semver.Version{
+				Prefix: new("v"),
+				Major:  "1",
+				Minor:  "2",
+				Patch:  "3",
+			},
+			expected: "v1.2.3",
+		},
+		{
+			version: &semver.Version{
+				Prefix:     new("v"),
+				Major:      "1",
+				Minor:      "2",
+				Patch:      "3",
+				PreRelease: []string{"alpha", "1"},
+			},
+			expected: "v1.2.3-alpha.1",
+		},
+		{
+			version: &semver.Version{
+				Prefix:     new("v"),
+				Major:      "1",
+				Minor:      "2",
+				Patch:      "3",
+				PreRelease: []string{"alpha", "1"},
+				Build:      new("build.123"),
+			},
+			expected: "v1.2.3-alpha.1+build.123",
+		},
+		{
+			version: &semver.Version{
+				Major: "1",
+				Minor: "2",
+				Patch: "3",
+				Build: new("build.123"),
+			},
+			expected: "1.2.3+build.123",
+		},
+		{
+			version: &semver.Version{
+				Major:      "1",
+				Minor:      "2",
+				Patch:      "3",
+				PreRelease: []string{"beta"},
+			},
+			expected: "1.2.3-beta",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.expected, func(t *testing.T) {
+			actual := tc.version.String()
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestBumpLarge(t *testing.T) {
+	v, err := semver.Parse("v9223372036854775807.99.999")
+	require.NoError(t, err)
+
+	assert.Equal(t, "v9223372036854775808.0.0", semver.BumpMajor(v).String())
+	assert.Equal(t, "v9223372036854775807.100.0", semver.BumpMinor(v).String())
+	assert.Equal(t, "v9223372036854775807.99.1000", semver.BumpPatch(v).String())
+
+	_, err = v.Major.Int()
+	assert.NoError(t, err)
+	_, err = semver.BumpMajor(v).Major.Int()
+	assert.EqualError(t, err, "9223372036854775808 is too large")
+}
+
+func TestBumpPreRelease(t *testing.T) {
+	type test struct {
+		version  *semver.Version
+		expected *semver.Version
+		err      bool
+	}
+
+	tests := []test{
+		{
+			version: &semver.Version{
+				Major: "1",
+				Minor: "2",
+				Patch: "3",
+			},
+			expected: nil,
+			err:      true,
+		},
+		{
+			version: &semver.Version{
+				Major:      "1",
+				Minor:      "2",
+				Patch:      "3",
+				PreRelease: []string{"alpha"},
+			},
+			expected: &semver.Version{
+				Major:      "1",
+				Minor:      "2",
+				Patch:      "3",
+				PreRelease: []string{"alpha", "1"},
+			},
+			err: false,
+		},
+		{
+			version: &semver.Version{
+				Major:      "1",
+				Minor:      "2",
+				Patch:      "3",
+				PreRelease: []string{"alpha", "1"},
+			},
+			expected: &semver.Version{
+				Major:      "1",
+				Minor:      "2",
+				Patch:      "3",
+				PreRelease: []string{"alpha", "2"},
+			},
+			err: false,
+		},
+		{
+			version: &semver.Version{
+				Major:      "1",
+				Minor:      "2",
+				Patch:      "3",
+				PreRelease: []string{"alpha", "beta"},
+			},
+			expected: &semver.Version{
+				Major:      "1",
+				Minor:      "2",
+				Patch:      "3",
+				PreRelease: []string{"alpha", "beta", "1"},
+			},
+			err: false,
+		},
+		{
+			version: &semver.Version{
+				Major:      "1",
+				Minor:      "2",
+				Patch:      "3",
+				PreRelease: []string{"alpha", "beta", "1"},
+			},
+			expected: &semver.Version{
+				Major:      "1",
+				Minor:      "2",
+				Patch:      "3",
+				PreRelease: []string{"alpha", "beta", "2"},
+			},
+			err: false,
+		},
+		{
+			version: &semver.Version{
+				Major:      "1",
+				Minor:      "2",
+				Patch:      "3",
+				PreRelease: []string{"rc", "99999999999999999999"},
+			},
+			expected: &semver.Version{
+				Major:      "1",
+				Minor:      "2",
+				Patch:      "3",
+				PreRelease: []string{"rc", "100000000000000000000"},
+			},
+			err: false,
+		},
+	}
+
+	for i, tc := range tests {
+		name := fmt.Sprintf("%d: %s is bumped to %s", i, tc.version, tc.expected)
+		t.Run(name, func(t *testing.T) {
+			actual, err := semver.BumpPreRelease(tc.version)
+			if tc.err {
+				require.Error(t, err)
+				assert.Nil(t, actual)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tc.expected.String(), actual.String())
+			}
+		})
+	}
+}
+
+func TestParseStrict(t *testing.T) {
+	type test struct {
+		version  string
+		expected string
+		err      string
+	}
+
+	tests := []test{
+		{version: "1.2.3", expected: "1.2.3"},
+		{version: "v1.2.3-alpha.1+build.001", expected: "v1.2.3-alpha.1+build.001"},
+		{version: "component-1.0.0-x-y.0", expected: "component-1.0.0-x-y.0"},
+		{version: "1.0.0-0a.01a", expected: "1.0.0-0a.01a"},
+		{version: "latest", err: "not a version"},
+		{version: "1.2", err: "not a version"},
+		{version: "01.2.3", err: `major version "01" has a leading zero`},
+		{version: "1.02.3", err: `minor version "02" has a leading zero`},
+		{version: "1.2.3.4", err: `"1.2.3.4" must be MAJOR.MINOR.PATCH`},
+		{version: "1.2.3x", err: `patch version "3x" is not a number`},
+		{version: "1.99999999999999999999.0", expected: "1.99999999999999999999.0"},
+		{version: "1.2.3-alpha.01", err: `pre-release identifier "01" has a leading zero`},
+		{version: "1.2.3-alpha..1", err: "pre-release identifiers can not be empty"},
+		{version: "1.2.3-", err: "pre-release identifiers can not be empty"},
+		{version: "1.2.3-alpha_1", err: `pre-release identifier "alpha_1" contains '_'`},
+		{version: "1.2.3+build.", err: "build metadata identifiers can not be empty"},
+		{version: "1.2.3+a+b", err: `build metadata identifier "a+b" contains '+'`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.version, func(t *testing.T) {
+			actual, err := semver.ParseStrict(tc.version)
+			if tc.err != "" {
+				assert.ErrorContains(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual.String())
+		})
+	}
+
+	_, err := semver.ParseStrict("release-candidate")
+	assert.ErrorIs(t, err, semver.ErrNoVersion)
+}
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, semver.Validate(semver.New(new("v"), "1", "0", "0", []string{"rc", "1"}, new("sha.5114f85"))))
+	assert.ErrorContains(t, semver.Validate(semver.New(new("app2-"), "1", "0", "0", nil, nil)), `prefix "app2-" can not contain digits`)
+	assert.ErrorContains(t, semver.Validate(semver.New(nil, "1", "0", "0", []string{"rc", "007"}, nil)), "leading zero")
+	assert.ErrorContains(t, semver.Validate(semver.New(nil, "1", "0", "0", nil, new("feature/x"))), `contains '/'`)
+}
+
+// semverValue generates random valid versions for property tests
+type semverValue struct {
+	version *semver.Version
+}
+
+const identifierChars = "0123456789abcxyzABCXYZ-"
+
+func randomNumeric(r *rand.Rand) string {
+	if r.Intn(4) == 0 {
+		return "0"
+	}
+	n := new(big.Int).Rand(r, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(1+r.Intn(30))), nil))
+	return n.Add(n, big.NewInt(1)).String()
+}
+
+// randomNumber returns a major, minor or patch version, sometimes beyond int64
+func randomNumber(r *rand.Rand) semver.Number {
+	if r.Intn(4) == 0 {
+		return semver.Number(randomNumeric(r))
+	}
+	return semver.Number(strconv.Itoa(r.Intn(100)))
+}
+
+// seed returns a seed for a random source from a version number
+func seed(n semver.Number) int64 {
+	s := n.String()
+	i, _ := strconv.ParseInt(s[:min(len(s), 18)], 10, 64)
+	return i
+}
+
+func randomIdentifier(r *rand.Rand) string {
+	b := strings.Builder{}
+	for range 1 + r.Intn(6) {
+		b.WriteByte(identifierChars[r.Intn(len(identifierChars))])
+	}
+	id := b.String()
+	if strings.Trim(id, "0123456789") == "" {
+		// all digits, make it alphanumeric
+		id += "x"
+	}
+	return id
+}
+
+func randomIdentifiers(r *rand.Rand, numeric bool) []string {
+	ids := make([]string, 1+r.Intn(4))
+	for i := range ids {
+		if r.Intn(2) == 0 {
+			ids[i] = randomIdentifier(r)
+		} else if numeric {
+			ids[i] = randomNumeric(r)
+		} else {
+			// build metadata allows leading zeros
+			ids[i] = "0" + randomNumeric(r)
+		}
+	}
+	return ids
+}
+
+func (semverValue) Generate(r *rand.Rand, _ int) reflect.Value {
+	v := &semver.Version{Major: randomNumber(r), Minor: randomNumber(r), Patch: randomNumber(r)}
+	if r.Intn(2) == 0 {
+		v.Prefix = new([]string{"v", "release-", "sub/dir/v"}[r.Intn(3)])
+	}
+	if r.Intn(2) == 0 {
+		v.PreRelease = randomIdentifiers(r, true)
+	}
+	if r.Intn(3) == 0 {
+		v.Build = new(strings.Join(randomIdentifiers(r, false), "."))
+	}
+	return reflect.ValueOf(semverValue{v})
+}
+
+func TestSemVerProperties(t *testing.T) {
+	t.Run("valid versions parse back to themselves", func(t *testing.T) {
+		f := func(s semverValue) bool {
+			strict, err := semver.ParseStrict(s.version.String())
+			if err != nil {
+				return false
+			}
+			loose, err := semver.Parse(s.version.String())
+			if err != nil {
+				return false
+			}
+			return strict.String() == s.version.String() && loose.String() == s.version.String()
+		}
+		assert.NoError(t, quick.Check(f, nil))
+	})
+
+	t.Run("leading zeros are rejected", func(t *testing.T) {
+		f := func(s semverValue) bool {
+			v := *s.version
+			v.PreRelease = append(append([]string{}, v.PreRelease...), "0"+randomNumeric(rand.New(rand.NewSource(seed(v.Major)))))
+			_, err := semver.ParseStrict(v.String())
+			return err != nil && strings.Contains(err.Error(), "leading zero")
+		}
+		assert.NoError(t, quick.Check(f, nil))
+	})
+
+	t.Run("versions equal themselves", func(t *testing.T) {
+		f := func(s semverValue) bool {
+			return semver.Compare(*s.version, *s.version) == 0
+		}
+		assert.NoError(t, quick.Check(f, nil))
+	})
+
+	t.Run("numeric identifiers compare numerically", func(t *testing.T) {
+		f := func(a, b semverValue) bool {
+			x := randomNumeric(rand.New(rand.NewSource(seed(a.version.Major))))
+			y := randomNumeric(rand.New(rand.NewSource(seed(b.version.Minor))))
+			v1 := semver.New(nil, "1", "0", "0", []string{"rc", x}, nil)
+			v2 := semver.New(nil, "1", "0", "0", []string{"rc", y}, nil)
+			xn, _ := new(big.Int).SetString(x, 10)
+			yn, _ := new(big.Int).SetString(y, 10)
+			return semver.Compare(*v1, *v2) == xn.Cmp(yn)
+		}
+		assert.NoError(t, quick.Check(f, nil))
+	})
+
+	t.Run("compare is antisymmetric", func(t *testing.T) {
+		f := func(a, b semverValue) bool {
+			return semver.Compare(*a.version, *b.version) == -semver.Compare(*b.version, *a.version)
+		}
+		assert.NoError(t, quick.Check(f, nil))
+	})
+
+	t.Run("compare is transitive", func(t *testing.T) {
+		f := func(a, b, c semverValue) bool {
+			versions := semver.Versions{a.version, b.version, c.version}
+			sort.Sort(versions)
+			// sorting only compares neighbours, so the ends must be in order too
+			return !versions[2].Less(versions[0])
+		}
+		assert.NoError(t, quick.Check(f, nil))
+	})
+
+	t.Run("more identifiers are greater", func(t *testing.T) {
+		f := func(s semverValue) bool {
+			if len(s.version.PreRelease) == 0 {
+				return true
+			}
+			longer := *s.version
+			longer.PreRelease = append(append([]string{}, s.version.PreRelease...), randomIdentifiers(rand.New(rand.NewSource(seed(s.version.Minor))), true)...)
+			return s.version.Less(&longer) && !longer.Less(s.version)
+		}
+		assert.NoError(t, quick.Check(f, nil))
+	})
+
+	t.Run("pre-releases are less than the release", func(t *testing.T) {
+		f := func(s semverValue) bool {
+			release := *s.version
+			release.PreRelease = nil
+			pre := release
+			pre.PreRelease = randomIdentifiers(rand.New(rand.NewSource(seed(s.version.Patch))), true)
+			return semver.Compare(pre, release) == -1 && semver.Compare(release, pre) == 1
+		}
+		assert.NoError(t, quick.Check(f, nil))
+	})
+
+	t.Run("bumped versions are greater", func(t *testing.T) {
+		f := func(s semverValue) bool {
+			return semver.Compare(*semver.BumpPatch(s.version), *s.version) == 1 &&
+				semver.Compare(*semver.BumpMinor(s.version), *s.version) == 1 &&
+				semver.Compare(*semver.BumpMajor(s.version), *s.version) == 1
+		}
+		assert.NoError(t, quick.Check(f, nil))
+	})
+}